@@ -0,0 +1,59 @@
+package axwayapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// The export of frontends, with their backends, security and applications,
+// as an encrypted collection to be imported in another API Manager (see axwayapi_api_import).
+var TFApiExportDataSchema = schemaMap{
+	"frontend_ids": desc(required(_pset(schema.TypeString)), "The ids of the frontends to export"),
+	"passphrase": desc(_sensitive(required(_string())),
+		"The passphrase the collection is encrypted with, and to give on its import"),
+	"content_base64": desc(readonly(_string()),
+		"The collection (a .dat file), base64 encoded. Being encrypted, it may differ from an export to another"),
+	"sha256": desc(readonly(_string()), "The hash of the collection"),
+}
+
+func dataSourceApiExport() *schema.Resource {
+	return &schema.Resource{
+		Schema:      TFApiExportDataSchema,
+		ReadContext: dataSourceApiExportRead,
+	}
+}
+
+func dataSourceApiExportRead(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ids := toStringArray(d.Get("frontend_ids"))
+	sort.Strings(ids)
+	form := url.Values{}
+	form.Set("filename", "api-export.dat")
+	form.Set("passphrase", d.Get("passphrase").(string))
+	for _, id := range ids {
+		form.Add("id", id)
+	}
+	b, err := restDo(c, "POST", "proxies/export", strings.NewReader(form.Encode()), "application/x-www-form-urlencoded")
+	if err != nil {
+		diags = append(diags, diag.Errorf("export of apis %q failed: %v", ids, err)...)
+		return diags
+	}
+
+	d.SetId(strings.Join(ids, ","))
+	d.Set("content_base64", base64.StdEncoding.EncodeToString(b))
+	d.Set("sha256", fmt.Sprintf("%x", sha256.Sum256(b)))
+
+	return diags
+}
@@ -0,0 +1,159 @@
+package axwayapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var TFBackendMethodsDataSchema = schemaMap{
+	"backend_id": required(_string()),
+	"method": readonly(_list(resource(schemaMap{
+		"id":          readonly(_string()),
+		"name":        desc(readonly(_string()), "The name of the method, usually the operationId in the spec"),
+		"summary":     readonly(_string()),
+		"description": readonly(_string()),
+		"verb":        readonly(_string()),
+		"path":        readonly(_string()),
+		"consumes":    readonly(_plist(schema.TypeString)),
+		"produces":    readonly(_plist(schema.TypeString)),
+		"parameter": readonly(_list(resource(schemaMap{
+			"name":        readonly(_string()),
+			"description": readonly(_string()),
+			"param_type":  desc(readonly(_string()), "Where the parameter is found: path, query, header, form or body"),
+			"type":        readonly(_string()),
+			"format":      readonly(_string()),
+			"required":    readonly(_bool()),
+		}))),
+	}))),
+	"ids": desc(readonly(_map(schema.TypeString)),
+		`The ids of the methods, by name and by "<VERB> <path>"`),
+	"model": readonly(_list(resource(schemaMap{
+		"name":        readonly(_string()),
+		"description": readonly(_string()),
+		"type":        readonly(_string()),
+		"properties": desc(readonly(_map(schema.TypeString)),
+			"The types of the properties, by name. A reference to another model is given by its name."),
+		"required": readonly(_plist(schema.TypeString)),
+		"schema":   desc(readonly(_string()), "The whole model, in JSON"),
+	}))),
+}
+
+func dataSourceBackendMethods() *schema.Resource {
+	return &schema.Resource{
+		Schema:      TFBackendMethodsDataSchema,
+		ReadContext: dataSourceBackendMethodsRead,
+	}
+}
+
+func dataSourceBackendMethodsRead(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	backendId := d.Get("backend_id").(string)
+	backend, err := c.GetBackend(backendId)
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+	methods, err := listBackendMethods(c, backendId)
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].Name < methods[j].Name })
+
+	r := make([]flattenMap, len(methods))
+	ids := make(map[string]interface{}, 2*len(methods))
+	for i, m := range methods {
+		params := make([]flattenMap, len(m.Params))
+		for j, p := range m.Params {
+			params[j] = flattenMap{
+				"name":        p.Name,
+				"description": p.Description,
+				"param_type":  p.ParamType,
+				"type":        p.Type,
+				"format":      p.Format,
+				"required":    p.Required,
+			}
+		}
+		r[i] = flattenMap{
+			"id":          m.Id,
+			"name":        m.Name,
+			"summary":     m.Summary,
+			"description": m.Description,
+			"verb":        m.Verb,
+			"path":        m.Path,
+			"consumes":    m.Consumes,
+			"produces":    m.Produces,
+			"parameter":   params,
+		}
+		ids[m.Name] = m.Id
+		if m.Path != "" {
+			ids[strings.ToUpper(m.Verb)+" "+m.Path] = m.Id
+		}
+	}
+	d.SetId(backendId)
+	d.Set("method", r)
+	d.Set("ids", ids)
+	d.Set("model", flattenModels(backend.Models))
+
+	return diags
+}
+
+// flattenModels gives the models of a backend, i.e. the JSON schemas of its spec, sorted by name.
+func flattenModels(models map[string]interface{}) []flattenMap {
+	names := make([]string, 0, len(models))
+	for name := range models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	r := make([]flattenMap, 0, len(models))
+	for _, name := range names {
+		model, _ := models[name].(map[string]interface{})
+		b, _ := json.Marshal(model)
+		description, _ := model["description"].(string)
+		ty, _ := model["type"].(string)
+		properties := map[string]interface{}{}
+		if props, ok := model["properties"].(map[string]interface{}); ok {
+			for k, v := range props {
+				properties[k] = modelPropertyType(v)
+			}
+		}
+		required := []string{}
+		if l, ok := model["required"].([]interface{}); ok {
+			for _, v := range l {
+				required = append(required, fmt.Sprint(v))
+			}
+		}
+		r = append(r, flattenMap{
+			"name":        name,
+			"description": description,
+			"type":        ty,
+			"properties":  properties,
+			"required":    required,
+			"schema":      string(b),
+		})
+	}
+	return r
+}
+
+// modelPropertyType gives the type of a property, e.g. "string", "array of Pet" or "Pet".
+func modelPropertyType(v interface{}) string {
+	p, _ := v.(map[string]interface{})
+	if ref, ok := p["$ref"].(string); ok {
+		return ref[strings.LastIndex(ref, "/")+1:]
+	}
+	ty, _ := p["type"].(string)
+	if ty == "array" {
+		return "array of " + modelPropertyType(p["items"])
+	}
+	return ty
+}
@@ -0,0 +1,76 @@
+package axwayapi
+
+import (
+	"context"
+	"fmt"
+
+	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Objects of the gateway referenced by their key, e.g. the OAuth token stores,
+// are looked up by name. Without a name, the gateway must have exactly one of them.
+func dataSourceNamedKey(what string, list func(c *client.Client) ([]Policy, error)) *schema.Resource {
+	return &schema.Resource{
+		Schema: schemaMap{
+			"name": desc(inOut(_string()), fmt.Sprintf("The name of the %s. May be omitted when there is only one.", what)),
+			"key":  desc(readonly(_string()), fmt.Sprintf("The key referencing the %s", what)),
+			"all": desc(readonly(_list(resource(schemaMap{
+				"name": readonly(_string()),
+				"key":  readonly(_string()),
+			}))), fmt.Sprintf("All the %ss known to the gateway", what)),
+		},
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+			c, err := m.(*ProviderState).GetClient()
+			if err != nil {
+				return diag.FromErr(err)
+			}
+
+			items, err := list(c)
+			if err != nil {
+				diags = append(diags, diag.FromErr(err)...)
+				return diags
+			}
+
+			all := make([]flattenMap, len(items))
+			names := make([]string, len(items))
+			var found *Policy
+			name, named := d.GetOk("name")
+			for i := range items {
+				all[i] = flattenMap{"name": items[i].Name, "key": items[i].Id}
+				names[i] = items[i].Name
+				if named && items[i].Name == name {
+					found = &items[i]
+				}
+			}
+			if !named && len(items) == 1 {
+				found = &items[0]
+			}
+			if found == nil {
+				if named {
+					return diag.Errorf("no %s named '%s', expecting one of %q", what, name, names)
+				}
+				return diag.Errorf("%d %ss found, a name is needed, one of %q", len(items), what, names)
+			}
+
+			d.SetId(found.Id)
+			d.Set("name", found.Name)
+			d.Set("key", found.Id)
+			d.Set("all", all)
+
+			return diags
+		},
+	}
+}
+
+func dataSourceTokenStore() *schema.Resource {
+	return dataSourceNamedKey("OAuth token store", listTokenStores)
+}
+
+// The policies usable in an "invoke policy" (authPolicy) security device.
+func dataSourceAuthenticationPolicy() *schema.Resource {
+	return dataSourceNamedKey("authentication policy", func(c *client.Client) ([]Policy, error) {
+		return listPolicies(c, "authentication")
+	})
+}
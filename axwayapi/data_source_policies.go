@@ -0,0 +1,54 @@
+package axwayapi
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var TFPoliciesDataSchema = schemaMap{
+	"type": desc(required(_string(oneOf(policyTypes...))),
+		"The type of the policies to list: request, response, routing, faulthandler, global or authentication"),
+	"policy": readonly(_list(resource(schemaMap{
+		"name": readonly(_string()),
+		"key":  desc(readonly(_string()), "The key referencing the policy, as expected by the policy attributes"),
+	}))),
+	"keys": desc(readonly(_map(schema.TypeString)), "The keys of the policies, by name"),
+}
+
+func dataSourcePolicies() *schema.Resource {
+	return &schema.Resource{
+		Schema:      TFPoliciesDataSchema,
+		ReadContext: dataSourcePoliciesRead,
+	}
+}
+
+func dataSourcePoliciesRead(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	policyType := d.Get("type").(string)
+	policies, err := listPolicies(c, policyType)
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+
+	r := make([]flattenMap, len(policies))
+	keys := make(map[string]interface{}, len(policies))
+	for i, p := range policies {
+		r[i] = flattenMap{
+			"name": p.Name,
+			"key":  p.Id,
+		}
+		keys[p.Name] = p.Id
+	}
+	d.SetId(policyType)
+	d.Set("policy", r)
+	d.Set("keys", keys)
+
+	return diags
+}
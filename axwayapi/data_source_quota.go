@@ -0,0 +1,118 @@
+package axwayapi
+
+import (
+	"context"
+	"log"
+
+	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	systemDefaultQuotaId      = "00000000-0000-0000-0000-000000000000"
+	applicationDefaultQuotaId = "00000000-0000-0000-0000-000000000001"
+)
+
+var TFQuotaDataSchema = schemaMap{
+	"application_id": desc(exactlyOneOfResource(_string(), "application_id", "default"),
+		"Reads the quota of this application"),
+	"default": desc(exactlyOneOfResource(_string(oneOf("system", "application")), "application_id", "default"),
+		"Reads the default quota: 'system' or 'application'"),
+	"name":        readonly(_string()),
+	"description": readonly(_string()),
+	"type":        readonly(_string()),
+	"system":      readonly(_bool()),
+	"restriction": readonly(_list(TFQuotaDataRestriction)),
+	"uses_default": desc(readonly(_bool()),
+		"Whether the application has no quota of its own: the application default quota then applies, and 'restriction' is empty"),
+}
+var TFQuotaDataRestriction = resource(schemaMap{
+	"api_id":         readonly(_string()),
+	"method":         readonly(_string()),
+	"limit":          desc(readonly(_string()), "The canonical form of the limit, e.g. '100msg/2minute'"),
+	"number":         desc(readonly(_int()), "The nb of messages or mega bytes allowed in the window"),
+	"unit":           desc(readonly(_string()), "'msg' or 'MB'"),
+	"per":            desc(readonly(_int()), "The nb of periods making the window"),
+	"period":         desc(readonly(_string()), "One of second, minute, hour, day, week, month"),
+	"window_seconds": desc(readonly(_int()), "The length of the window in seconds (a month counts 30 days)"),
+})
+
+var secondsPer = map[string]int{
+	"second": 1,
+	"minute": 60,
+	"hour":   60 * 60,
+	"day":    24 * 60 * 60,
+	"week":   7 * 24 * 60 * 60,
+	"month":  30 * 24 * 60 * 60,
+}
+
+func dataSourceQuota() *schema.Resource {
+	return &schema.Resource{
+		Schema:      TFQuotaDataSchema,
+		ReadContext: dataSourceQuotaRead,
+	}
+}
+
+func dataSourceQuotaRead(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// the ids are not the ones of the quotas: an application may have none of its own.
+	var quota *client.Quota
+	var id string
+	if appId, ok := d.GetOk("application_id"); ok {
+		quota = &client.Quota{}
+		err = c.GetQuotaForApplication(appId.(string), quota)
+		id = "application/" + appId.(string)
+	} else if d.Get("default").(string) == "system" {
+		quota, err = c.GetQuota(systemDefaultQuotaId)
+		id = "default/system"
+	} else {
+		quota, err = c.GetQuota(applicationDefaultQuotaId)
+		id = "default/application"
+	}
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+	nameRestrictionMethods(c, quota.Restrictions, nil)
+
+	d.SetId(id)
+	usesDefault := quota.Id == ""
+	if usesDefault {
+		log.Printf("[INFO] the application %s has no quota of its own, the application default quota applies", d.Get("application_id"))
+	}
+	d.Set("uses_default", usesDefault)
+	d.Set("name", quota.Name)
+	d.Set("description", quota.Description)
+	d.Set("type", quota.Type)
+	d.Set("system", quota.System)
+	d.Set("restriction", flattenQuotaDataRestrictions(quota.Restrictions))
+
+	return diags
+}
+
+func flattenQuotaDataRestrictions(restrictions []client.Constraint) []flattenMap {
+	r := make([]flattenMap, len(restrictions))
+	for i, a := range restrictions {
+		t := flattenThrottle(a.Config)
+		number, unit := t["messages"], "msg"
+		if t["mb"].(int) > 0 {
+			number, unit = t["mb"], "MB"
+		}
+		r[i] = flattenMap{
+			"api_id":         a.Api,
+			"method":         a.Method,
+			"limit":          flattenRestrictionConfig(a.Config),
+			"number":         number,
+			"unit":           unit,
+			"per":            t["per"],
+			"period":         t["period"],
+			"window_seconds": t["per"].(int) * secondsPer[t["period"].(string)],
+		}
+	}
+	return r
+}
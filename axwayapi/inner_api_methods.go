@@ -0,0 +1,231 @@
+package axwayapi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+)
+
+// A method of a backend, i.e. an operation of the imported spec.
+type BackendMethod struct {
+	Id          string               `json:"id"`
+	ApiId       string               `json:"apiId"`
+	Name        string               `json:"name"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Verb        string               `json:"verb,omitempty"`
+	Path        string               `json:"path,omitempty"`
+	Consumes    []string             `json:"consumes,omitempty"`
+	Produces    []string             `json:"produces,omitempty"`
+	Params      []BackendMethodParam `json:"params,omitempty"`
+}
+
+// A parameter of a backend method.
+type BackendMethodParam struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	ParamType   string `json:"paramType,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Format      string `json:"format,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// A method of a frontend, i.e. the virtualization of a backend method.
+type FrontendMethod struct {
+	Id               string `json:"id"`
+	VirtualizedApiId string `json:"virtualizedApiId"`
+	ApiMethodId      string `json:"apiMethodId"`
+	Name             string `json:"name"`
+	Summary          string `json:"summary,omitempty"`
+}
+
+func listBackendMethods(c *client.Client, backendId string) (ret []BackendMethod, err error) {
+	err = restGet(c, &ret, fmt.Sprintf("apirepo/%s/methods", backendId))
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func listFrontendMethods(c *client.Client, frontendId string) (ret []FrontendMethod, err error) {
+	err = restGet(c, &ret, fmt.Sprintf("proxies/%s/operations", frontendId))
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// errNoMethod tells that a method is not found, as opposed to failing to list the methods.
+var errNoMethod = errors.New("no method")
+
+// methodIndex resolves the methods of a frontend by name, either
+// the operation name (usually the operationId of the spec) or "VERB /path".
+type methodIndex struct {
+	frontendId string
+	methods    []FrontendMethod
+	backend    map[string]BackendMethod
+}
+
+func newMethodIndex(c *client.Client, frontendId string) (*methodIndex, error) {
+	frontend, err := c.GetFrontend(frontendId)
+	if err != nil {
+		return nil, err
+	}
+	methods, err := listFrontendMethods(c, frontendId)
+	if err != nil {
+		return nil, err
+	}
+	backendMethods, err := listBackendMethods(c, frontend.ApiId)
+	if err != nil {
+		return nil, err
+	}
+	idx := &methodIndex{frontendId: frontendId, methods: methods, backend: map[string]BackendMethod{}}
+	for _, m := range backendMethods {
+		idx.backend[m.Id] = m
+	}
+	return idx, nil
+}
+
+func (idx *methodIndex) verbAndPath(m FrontendMethod) string {
+	if b, ok := idx.backend[m.ApiMethodId]; ok && b.Path != "" {
+		return strings.ToUpper(b.Verb) + " " + b.Path
+	}
+	return ""
+}
+
+// resolve gives the id of the method designated by name. '*' stands for all methods.
+func (idx *methodIndex) resolve(name string) (string, error) {
+	if name == "*" {
+		return name, nil
+	}
+	for _, m := range idx.methods {
+		if m.Id == name || m.Name == name {
+			return m.Id, nil
+		}
+	}
+	if f := strings.Fields(name); len(f) == 2 {
+		vp := strings.ToUpper(f[0]) + " " + f[1]
+		for _, m := range idx.methods {
+			if idx.verbAndPath(m) == vp {
+				return m.Id, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w '%s' in api %s, expecting '*' or one of %q", errNoMethod, name, idx.frontendId, idx.names())
+}
+
+// nameOf gives the name of the method with the given id, or the id itself when unknown.
+func (idx *methodIndex) nameOf(id string) string {
+	for _, m := range idx.methods {
+		if m.Id == id && m.Name != "" {
+			return m.Name
+		}
+	}
+	return id
+}
+
+// apiMethodIdOf gives the id of the backend method virtualized by the given method.
+func (idx *methodIndex) apiMethodIdOf(id string) string {
+	for _, m := range idx.methods {
+		if m.Id == id {
+			return m.ApiMethodId
+		}
+	}
+	return ""
+}
+
+func (idx *methodIndex) names() []string {
+	r := make([]string, 0, 2*len(idx.methods))
+	for _, m := range idx.methods {
+		r = append(r, m.Name)
+		if vp := idx.verbAndPath(m); vp != "" {
+			r = append(r, vp)
+		}
+	}
+	sort.Strings(r)
+	return r
+}
+
+// methodIndexes caches the indexes for the duration of a single operation.
+type methodIndexes map[string]*methodIndex
+
+func (idxs methodIndexes) get(c *client.Client, frontendId string) (*methodIndex, error) {
+	if idx, ok := idxs[frontendId]; ok {
+		return idx, nil
+	}
+	idx, err := newMethodIndex(c, frontendId)
+	if err != nil {
+		return nil, err
+	}
+	idxs[frontendId] = idx
+	return idx, nil
+}
+
+// backendMethodIndexes resolves the methods of backends by name, either the
+// operation name (usually the operationId of the spec) or "VERB /path".
+// The methods of each backend are listed once, for the duration of a single operation.
+type backendMethodIndexes map[string][]BackendMethod
+
+func (idxs backendMethodIndexes) get(c *client.Client, backendId string) ([]BackendMethod, error) {
+	if methods, ok := idxs[backendId]; ok {
+		return methods, nil
+	}
+	methods, err := listBackendMethods(c, backendId)
+	if err != nil {
+		return nil, err
+	}
+	idxs[backendId] = methods
+	return methods, nil
+}
+
+// resolve replaces the method designated by *ref by its id.
+func (idxs backendMethodIndexes) resolve(c *client.Client, backendId string, ref *string) error {
+	if backendId == "" || *ref == "" {
+		return nil
+	}
+	methods, err := idxs.get(c, backendId)
+	if err != nil {
+		return err
+	}
+	id, err := resolveBackendMethod(methods, *ref)
+	if err != nil {
+		return fmt.Errorf("%w in backend %s", err, backendId)
+	}
+	*ref = id
+	return nil
+}
+
+// nameOf gives back the prior name of the method with the given id, if it still designates it.
+func (idxs backendMethodIndexes) nameOf(c *client.Client, backendId, id string, prior interface{}) string {
+	name, ok := prior.(string)
+	if !ok || name == "" || name == id || backendId == "" {
+		return id
+	}
+	methods, err := idxs.get(c, backendId)
+	if err != nil {
+		return id
+	}
+	if r, err := resolveBackendMethod(methods, name); err == nil && r == id {
+		return name
+	}
+	return id
+}
+
+func resolveBackendMethod(methods []BackendMethod, name string) (string, error) {
+	for _, m := range methods {
+		if m.Id == name || m.Name == name {
+			return m.Id, nil
+		}
+	}
+	if f := strings.Fields(name); len(f) == 2 {
+		for _, m := range methods {
+			if strings.EqualFold(m.Verb, f[0]) && m.Path == f[1] {
+				return m.Id, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w '%s'", errNoMethod, name)
+}
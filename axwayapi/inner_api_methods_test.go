@@ -0,0 +1,113 @@
+package axwayapi
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var petMethods = []BackendMethod{
+	{Id: "b1", Name: "listPets", Verb: "GET", Path: "/pets"},
+	{Id: "b2", Name: "getPet", Verb: "GET", Path: "/pets/{id}"},
+	{Id: "b3", Name: "deletePet", Verb: "DELETE", Path: "/pets/{id}"},
+	// an operation named like the path of another.
+	{Id: "b4", Name: "GET /pets/{id}", Verb: "POST", Path: "/pets/{id}"},
+}
+
+func TestResolveBackendMethod(t *testing.T) {
+	for _, tt := range []struct {
+		name, want string
+	}{
+		{"b2", "b2"},
+		{"listPets", "b1"},
+		{"DELETE /pets/{id}", "b3"},
+		{"delete   /pets/{id}", "b3"},
+		// the names are looked for before the verbs and paths.
+		{"GET /pets/{id}", "b4"},
+		{"get /pets/{id}", "b2"},
+		{"POST /pets/{id}", "b4"},
+	} {
+		if got, err := resolveBackendMethod(petMethods, tt.name); err != nil || got != tt.want {
+			t.Errorf("%s: got %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+	for _, name := range []string{"", "ListPets", "GET /Pets", "PUT /pets", "GET /pets extra", "GET"} {
+		if got, err := resolveBackendMethod(petMethods, name); !errors.Is(err, errNoMethod) {
+			t.Errorf("%q: got %q, %v, want no method", name, got, err)
+		}
+	}
+}
+
+func TestMethodIndex(t *testing.T) {
+	idx := &methodIndex{
+		frontendId: "f",
+		methods: []FrontendMethod{
+			{Id: "m1", ApiMethodId: "b1", Name: "listPets"},
+			{Id: "m2", ApiMethodId: "b2", Name: "getPet"},
+			// a method whose backend method is unknown.
+			{Id: "m5", ApiMethodId: "b5", Name: "other"},
+		},
+		backend: map[string]BackendMethod{},
+	}
+	for _, m := range petMethods {
+		idx.backend[m.Id] = m
+	}
+	for _, tt := range []struct {
+		name, want string
+	}{
+		{"*", "*"},
+		{"m2", "m2"},
+		{"listPets", "m1"},
+		{"get /pets/{id}", "m2"},
+		{"other", "m5"},
+	} {
+		if got, err := idx.resolve(tt.name); err != nil || got != tt.want {
+			t.Errorf("%s: got %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+	if got, err := idx.resolve("DELETE /pets/{id}"); !errors.Is(err, errNoMethod) {
+		t.Errorf("got %q, %v, want no method", got, err)
+	}
+	if got, want := idx.names(), []string{"GET /pets", "GET /pets/{id}", "getPet", "listPets", "other"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got names %q, want %q", got, want)
+	}
+	if got := idx.nameOf("m1"); got != "listPets" {
+		t.Errorf("got %q, want listPets", got)
+	}
+	if got := idx.nameOf("gone"); got != "gone" {
+		t.Errorf("got %q, want the id", got)
+	}
+	if got := idx.apiMethodIdOf("m2"); got != "b2" {
+		t.Errorf("got %q, want b2", got)
+	}
+}
+
+func TestBackendMethodNameOf(t *testing.T) {
+	// already listed, the client is not used.
+	idxs := backendMethodIndexes{"b": petMethods}
+	for _, tt := range []struct {
+		id    string
+		prior interface{}
+		want  string
+	}{
+		{"b1", "listPets", "listPets"},
+		{"b2", "GET /pets/{id}", "b2"},
+		{"b2", "get /pets/{id}", "get /pets/{id}"},
+		// the prior name designates another method now.
+		{"b3", "listPets", "b3"},
+		{"b1", nil, "b1"},
+		{"b1", "", "b1"},
+	} {
+		if got := idxs.nameOf(nil, "b", tt.id, tt.prior); got != tt.want {
+			t.Errorf("%s as %v: got %q, want %q", tt.id, tt.prior, got, tt.want)
+		}
+	}
+	ref := "deletePet"
+	if err := idxs.resolve(nil, "b", &ref); err != nil || ref != "b3" {
+		t.Errorf("got %q, %v, want b3", ref, err)
+	}
+	ref = "nope"
+	if err := idxs.resolve(nil, "b", &ref); !errors.Is(err, errNoMethod) || ref != "nope" {
+		t.Errorf("got %q, %v, want no method", ref, err)
+	}
+}
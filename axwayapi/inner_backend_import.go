@@ -0,0 +1,121 @@
+package axwayapi
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+
+	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// The client only imports Swagger 2 content: the specs of the other types,
+// and the ones found at an url, are imported here.
+
+// importBackend imports the spec configured for the backend, giving the type it was imported as.
+func importBackend(ctx context.Context, m interface{}, c *client.Client, d *schema.ResourceData) (*client.Backend, string, error) {
+	orgId := d.Get("org_id").(string)
+	name := d.Get("name").(string)
+	ty := d.Get("type").(string)
+
+	if u := d.Get("url").(string); u != "" {
+		if ty == "" {
+			spec, err := fetchSpec(ctx, m, u)
+			if err != nil {
+				return nil, "", err
+			}
+			if ty, err = specType(spec); err != nil {
+				return nil, "", fmt.Errorf("%s: %w", u, err)
+			}
+		}
+		form := url.Values{}
+		form.Set("organizationId", orgId)
+		form.Set("name", name)
+		form.Set("type", ty)
+		form.Set("url", u)
+		backend := &client.Backend{}
+		err := restPostForm(c, backend, "apirepo/importFromUrl", form)
+		return backend, ty, err
+	}
+
+	spec, err := normalizeSpec(d.Get("swagger").(string))
+	if err != nil {
+		return nil, "", err
+	}
+	if ty == "" {
+		if ty, err = specType(spec); err != nil {
+			return nil, "", err
+		}
+	}
+	if ty == swaggerSpec {
+		backend, err := c.CreateBackend(orgId, name, ty, spec)
+		return backend, ty, err
+	}
+
+	fileName, contentType, content := "api.json", "application/json", []byte(spec)
+	if ty == wsdlSpec {
+		fileName, contentType = "api.wsdl", "text/xml"
+		if imports := d.Get("imports").(map[string]interface{}); len(imports) > 0 {
+			// the WSDL is sent in an archive, next to the files it imports.
+			if content, err = zipFiles(fileName, spec, imports); err != nil {
+				return nil, "", err
+			}
+			fileName, contentType = "api.zip", "application/zip"
+		}
+	}
+	fields := map[string]string{"name": name, "type": ty, "organizationId": orgId}
+	b, err := restPostParts(c, "apirepo/import", fields, restFile{"file", fileName, contentType, content})
+	if err != nil {
+		return nil, "", fmt.Errorf("import of the %s spec failed: %w", ty, err)
+	}
+	backend := &client.Backend{}
+	return backend, ty, json.Unmarshal(b, backend)
+}
+
+// downloadBackendSpec gives the spec the backend was imported from, as kept by the server.
+func downloadBackendSpec(c *client.Client, backendId string) (string, error) {
+	b, err := restDo(c, "GET", fmt.Sprintf("apirepo/%s/download?original=true", backendId), nil, "")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// fetchSpec reads the spec at the url, only to detect its type:
+// the API Manager imports it by itself.
+func fetchSpec(ctx context.Context, m interface{}, u string) (string, error) {
+	b, err := download(ctx, m, u)
+	if err != nil {
+		return "", fmt.Errorf("cannot read the spec: %w", err)
+	}
+	return string(b), nil
+}
+
+func zipFiles(main, content string, others map[string]interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	z := zip.NewWriter(buf)
+	names := []string{main}
+	files := map[string]string{main: content}
+	for k, v := range others {
+		names = append(names, k)
+		files[k] = v.(string)
+	}
+	sort.Strings(names[1:])
+	for _, name := range names {
+		f, err := z.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = f.Write([]byte(files[name])); err != nil {
+			return nil, err
+		}
+	}
+	if err := z.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
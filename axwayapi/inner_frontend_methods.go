@@ -0,0 +1,248 @@
+package axwayapi
+
+import (
+	"fmt"
+
+	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// The overrides of a single method of a frontend.
+// The API Manager keys the method-level inbound and outbound profiles by the id
+// of the method, next to the API-wide '_default' ones which give the values
+// of the attributes not overridden here.
+var TFFrontendMethod = resource(schemaMap{
+	"name": desc(required(_string()),
+		`The method: its name (usually the operationId in the spec) or "<VERB> <path>", e.g. "GET /pets/{id}"`),
+	"id": desc(readonly(_string()), "The id of the method in the frontend"),
+	// inbound
+	"security_profile": inOut(_string()),
+	"cors_profile":     inOut(_string()),
+	"monitor_api":      desc(inOut(_bool()), "When not set, the one of the frontend is kept"),
+	"monitor_subject":  inOut(_string()),
+	// outbound
+	"authentication_profile": inOut(_string()),
+	"route_type":             inOut(_string()),
+	"request_policy":         desc(inOut(_string()), "The key or the name of a 'request' policy"),
+	"response_policy":        desc(inOut(_string()), "The key or the name of a 'response' policy"),
+	"route_policy":           desc(inOut(_string()), "The key or the name of a 'routing' policy"),
+	"fault_handler_policy":   desc(inOut(_string()), "The key or the name of a 'faulthandler' policy"),
+	// documentation
+	"tag":         optional(_setMin(1, TFTag)),
+	"description": desc(optional(_string()), "Replaces the description of the method found in the spec"),
+})
+
+// expandFrontendMethods applies the method blocks onto the profiles of the frontend,
+// which must exist already for its methods to be resolved.
+func expandFrontendMethods(c *client.Client, d *schema.ResourceData, frontend *client.Frontend) error {
+	if frontend.InboundProfiles == nil {
+		frontend.InboundProfiles = map[string]client.InboundProfile{}
+	}
+	if frontend.OutboundProfiles == nil {
+		frontend.OutboundProfiles = map[string]client.OutboundProfile{}
+	}
+	// The methods no longer overridden fall back to the defaults.
+	old, _ := d.GetChange("method")
+	for _, id := range frontendMethodIds(old) {
+		delete(frontend.InboundProfiles, id)
+		delete(frontend.OutboundProfiles, id)
+	}
+
+	methods := d.Get("method").([]interface{})
+	if len(methods) == 0 {
+		return nil
+	}
+	idx, err := newMethodIndex(c, frontend.Id)
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for i, b := range methods {
+		a := b.(map[string]interface{})
+		name := a["name"].(string)
+		id, err := idx.resolve(name)
+		if err != nil {
+			return err
+		}
+		if id == "*" {
+			return fmt.Errorf("a method block designates a single method, use the profiles of the frontend for all of them")
+		}
+		if seen[id] {
+			return fmt.Errorf("the method '%s' is given more than once", name)
+		}
+		seen[id] = true
+		a["id"] = id
+
+		in := frontend.InboundProfiles["_default"]
+		override(&in.SecurityProfile, a["security_profile"])
+		override(&in.CorsProfile, a["cors_profile"])
+		// false cannot be told apart from unset but in the configuration.
+		if v := configuredMethodAttr(d, i, "monitor_api"); v.IsKnown() && !v.IsNull() {
+			in.MonitorAPI = v.True()
+		}
+		override(&in.MonitorSubject, a["monitor_subject"])
+		frontend.InboundProfiles[id] = in
+
+		out := frontend.OutboundProfiles["_default"]
+		out.ApiMethodId = idx.apiMethodIdOf(id)
+		override(&out.AuthenticationProfile, a["authentication_profile"])
+		override(&out.RouteType, a["route_type"])
+		override(&out.RequestPolicy, a["request_policy"])
+		override(&out.ResponsePolicy, a["response_policy"])
+		override(&out.RoutePolicy, a["route_policy"])
+		override(&out.FaultHandlerPolicy, a["fault_handler_policy"])
+		frontend.OutboundProfiles[id] = out
+	}
+	// keep the resolved ids, so that the profiles of the methods are told apart from the others.
+	d.Set("method", methods)
+	return nil
+}
+
+// configuredMethodAttr gives an attribute of a method block as configured, null when it is not.
+func configuredMethodAttr(d *schema.ResourceData, i int, attr string) cty.Value {
+	methods := d.GetRawConfig()
+	if !methods.IsKnown() || methods.IsNull() {
+		return cty.NullVal(cty.DynamicPseudoType)
+	}
+	methods = methods.GetAttr("method")
+	if !methods.IsKnown() || methods.IsNull() || methods.LengthInt() <= i {
+		return cty.NullVal(cty.DynamicPseudoType)
+	}
+	return methods.Index(cty.NumberIntVal(int64(i))).GetAttr(attr)
+}
+
+func override(dst *string, v interface{}) {
+	if s, ok := v.(string); ok && s != "" {
+		*dst = s
+	}
+}
+
+// syncFrontendMethods updates the tags and description of the methods.
+// The operations are handled as raw objects, so that the rest of them is left untouched.
+func syncFrontendMethods(c *client.Client, d *schema.ResourceData, frontend *client.Frontend) (diags diag.Diagnostics) {
+	for _, b := range d.Get("method").([]interface{}) {
+		a := b.(map[string]interface{})
+		url := fmt.Sprintf("proxies/%s/operations/%s", frontend.Id, a["id"])
+		op := map[string]interface{}{}
+		if err := restGet(c, &op, url); err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+			return diags
+		}
+		op["tags"] = toTags(a["tag"].(*schema.Set))
+		if description := a["description"].(string); description != "" {
+			op["descriptionType"] = "manual"
+			op["descriptionManual"] = description
+		} else {
+			op["descriptionType"] = "original"
+		}
+		if err := restPut(c, &op, url); err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+			return diags
+		}
+	}
+	return diags
+}
+
+// flattenFrontendMethods refreshes the method blocks, in the order they are configured.
+// The methods gone from the frontend are dropped.
+func flattenFrontendMethods(c *client.Client, d *schema.ResourceData, frontend *client.Frontend) (diags diag.Diagnostics) {
+	prior := d.Get("method").([]interface{})
+	if len(prior) == 0 {
+		return diags
+	}
+	idx, err := newMethodIndex(c, frontend.Id)
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+	policies := newPolicyIndex(c)
+	r := make([]flattenMap, 0, len(prior))
+	for _, b := range prior {
+		a := b.(map[string]interface{})
+		id := a["id"].(string)
+		if id == "" {
+			if id, err = idx.resolve(a["name"].(string)); err != nil {
+				continue
+			}
+		}
+		if _, err := idx.resolve(id); err != nil {
+			// not a method of this frontend (anymore).
+			continue
+		}
+		op := struct {
+			Tags              map[string][]string `json:"tags"`
+			DescriptionType   string              `json:"descriptionType"`
+			DescriptionManual string              `json:"descriptionManual"`
+		}{}
+		if err := restGet(c, &op, fmt.Sprintf("proxies/%s/operations/%s", frontend.Id, id)); err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+			return diags
+		}
+		description := ""
+		if op.DescriptionType == "manual" {
+			description = op.DescriptionManual
+		}
+		in := frontend.InboundProfiles[id]
+		out := frontend.OutboundProfiles[id]
+		namePolicies(policies, outboundPolicies(&out), func(attr string) interface{} { return a[attr] })
+		m := flattenMap{
+			"name":                   a["name"],
+			"id":                     id,
+			"security_profile":       in.SecurityProfile,
+			"cors_profile":           in.CorsProfile,
+			"monitor_api":            in.MonitorAPI,
+			"monitor_subject":        in.MonitorSubject,
+			"authentication_profile": out.AuthenticationProfile,
+			"route_type":             out.RouteType,
+			"request_policy":         out.RequestPolicy,
+			"response_policy":        out.ResponsePolicy,
+			"route_policy":           out.RoutePolicy,
+			"fault_handler_policy":   out.FaultHandlerPolicy,
+			"description":            description,
+		}
+		if len(op.Tags) > 0 {
+			m["tag"] = flattenTags(op.Tags)
+		}
+		r = append(r, m)
+	}
+	d.Set("method", r)
+	return diags
+}
+
+// frontendMethodIds gives the ids of the methods of the given method blocks.
+func frontendMethodIds(methods interface{}) (r []string) {
+	l, _ := methods.([]interface{})
+	for _, b := range l {
+		if a, ok := b.(map[string]interface{}); ok {
+			if id, _ := a["id"].(string); id != "" {
+				r = append(r, id)
+			}
+		}
+	}
+	return r
+}
+
+// The profiles of the frontend, but the ones of the method blocks.
+func inboundProfilesExcept(profiles map[string]client.InboundProfile, ids []string) map[string]client.InboundProfile {
+	r := make(map[string]client.InboundProfile, len(profiles))
+	for k, v := range profiles {
+		r[k] = v
+	}
+	for _, id := range ids {
+		delete(r, id)
+	}
+	return r
+}
+
+func outboundProfilesExcept(profiles map[string]client.OutboundProfile, ids []string) map[string]client.OutboundProfile {
+	r := make(map[string]client.OutboundProfile, len(profiles))
+	for k, v := range profiles {
+		r[k] = v
+	}
+	for _, id := range ids {
+		delete(r, id)
+	}
+	return r
+}
@@ -0,0 +1,218 @@
+package axwayapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// The states of a frontend, beyond published, unpublished and deprecated.
+// A frontend is pending while its publication awaits an approval, and
+// retired once deprecated and past its retirement date.
+// 'pending' is shared with the applications.
+const retired = "retired"
+
+// The actions taken on a frontend to bring it from a state to another.
+type frontendAction string
+
+const (
+	publishAction     frontendAction = "publish"
+	unpublishAction   frontendAction = "unpublish"
+	deprecateAction   frontendAction = "deprecate"
+	undeprecateAction frontendAction = "undeprecate"
+	retireAction      frontendAction = "retire"
+	// Nothing can be done but waiting for someone to approve the publication.
+	awaitApprovalAction frontendAction = "await approval"
+)
+
+// The state machine of the frontends: the actions to take, by current and by wanted state.
+// Transitions missing here are not possible, e.g. a retired frontend cannot be published again.
+var frontendTransitions = map[string]map[string][]frontendAction{
+	unpublished: {
+		published:  {publishAction},
+		deprecated: {publishAction, deprecateAction},
+		retired:    {publishAction, retireAction},
+	},
+	pending: {
+		unpublished: {unpublishAction},
+		published:   {awaitApprovalAction},
+		deprecated:  {awaitApprovalAction},
+		retired:     {awaitApprovalAction},
+	},
+	published: {
+		unpublished: {unpublishAction},
+		deprecated:  {deprecateAction},
+		retired:     {retireAction},
+	},
+	deprecated: {
+		unpublished: {undeprecateAction, unpublishAction},
+		published:   {undeprecateAction},
+		retired:     {undeprecateAction, retireAction},
+	},
+	retired: {
+		unpublished: {unpublishAction},
+	},
+}
+
+// frontendTransition gives the actions bringing a frontend from a state to another.
+func frontendTransition(from, to string) ([]frontendAction, error) {
+	if from == to {
+		return nil, nil
+	}
+	if actions, ok := frontendTransitions[from][to]; ok {
+		return actions, nil
+	}
+	return nil, fmt.Errorf("a frontend cannot go from %s to %s", from, to)
+}
+
+// frontendState gives the state of a frontend as seen by terraform, when the given state is wanted.
+// Retiring is deprecating with a retirement date: the server tells the frontend retired only
+// once this date is past. Until then, it is retired already when so wanted on this date,
+// or on any date when none is given.
+func frontendState(f *client.Frontend, want string, retirement time.Time) string {
+	switch {
+	case f.Retired:
+		return retired
+	case f.Deprecated && f.State == published:
+		if want == retired && f.RetirementDate != 0 && (retirement.IsZero() || retirementDateOf(f).Equal(retirement)) {
+			return retired
+		}
+		return deprecated
+	default:
+		return f.State
+	}
+}
+
+// adaptStates brings the frontend to the wanted state, through the actions of the state machine.
+// The retirement date, if any, is used when deprecating or retiring.
+func adaptStates(c *client.Client, state string, retirement time.Time, frontend *client.Frontend) (diags diag.Diagnostics) {
+	actions, err := frontendTransition(frontendState(frontend, state, retirement), state)
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+	for _, action := range actions {
+		if action == awaitApprovalAction || (frontend.State == pending && action != unpublishAction) {
+			return warn(diags, "the publication of the api %s awaits approval: it will become %s once approved and applied again", frontend.Name, state)
+		}
+		switch action {
+		case publishAction:
+			err = c.PublishFrontend(frontend)
+		case unpublishAction:
+			err = c.UnpublishFrontend(frontend)
+		case deprecateAction:
+			err = deprecateFrontend(c, frontend, retirement)
+		case undeprecateAction:
+			err = c.UndeprecateFrontend(frontend)
+		case retireAction:
+			// retiring is deprecating, effective immediately unless a retirement date is given.
+			date := retirement
+			if date.IsZero() {
+				date = time.Now()
+			}
+			err = deprecateFrontend(c, frontend, date)
+		}
+		if err != nil {
+			diags = append(diags, diag.Errorf("%s of api %s failed: %v", action, frontend.Name, err)...)
+			return diags
+		}
+	}
+	if frontend.State == pending && state != pending {
+		return warn(diags, "the publication of the api %s awaits approval: it will become %s once approved and applied again", frontend.Name, state)
+	}
+	return diags
+}
+
+func deprecateFrontend(c *client.Client, frontend *client.Frontend, retirement time.Time) error {
+	form := url.Values{}
+	if !retirement.IsZero() {
+		form.Set("retirementDate", retirement.UTC().Format(time.RFC3339))
+	}
+	return restPostForm(c, frontend, fmt.Sprintf("proxies/%s/deprecate", frontend.Id), form)
+}
+
+// retirementDate gives the configured retirement date, if any.
+func retirementDate(d *schema.ResourceData) time.Time {
+	t, _ := time.Parse(time.RFC3339, d.Get("retirement_date").(string))
+	return t
+}
+
+// retirementDateOf gives the retirement date of the frontend, if any.
+func retirementDateOf(frontend *client.Frontend) time.Time {
+	if frontend.RetirementDate == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(frontend.RetirementDate)*int64(time.Millisecond))
+}
+
+// The server counts the retirement date in milliseconds since the epoch.
+func expandRetirementDate(s string) int {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0
+	}
+	return int(t.UnixNano() / int64(time.Millisecond))
+}
+
+// flattenRetirementDate keeps the prior date when it designates the same instant,
+// e.g. when given with another time zone than UTC.
+func flattenRetirementDate(millis int, prior interface{}) string {
+	if millis == 0 {
+		return ""
+	}
+	if p, ok := prior.(string); ok && expandRetirementDate(p) == millis {
+		return p
+	}
+	return time.Unix(0, int64(millis)*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+}
+
+func validRFC3339(value interface{}, path cty.Path) diag.Diagnostics {
+	if _, err := time.Parse(time.RFC3339, value.(string)); err != nil {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       fmt.Sprintf("'%s' is not a RFC 3339 date, e.g. 2023-06-30T00:00:00Z", value),
+			AttributePath: path,
+		}}
+	}
+	return nil
+}
+
+// resourceFrontendV0 is the frontend before its retirement date was given in RFC 3339:
+// it was then the milliseconds since the epoch, as counted by the server.
+func resourceFrontendV0() *schema.Resource {
+	s := schemaMap{}
+	for k, v := range *TFFrontendSchema {
+		s[k] = v
+	}
+	s["retirement_date"] = inOut(_int())
+	return &schema.Resource{Schema: s}
+}
+
+func upgradeRetirementDate(ctx context.Context, rawState map[string]interface{}, m interface{}) (map[string]interface{}, error) {
+	var millis int64
+	switch v := rawState["retirement_date"].(type) {
+	case nil:
+	case float64:
+		millis = int64(v)
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid retirement date %s: %w", v, err)
+		}
+		millis = n
+	case string:
+		// already upgraded.
+		return rawState, nil
+	default:
+		return nil, fmt.Errorf("invalid retirement date %v", v)
+	}
+	rawState["retirement_date"] = flattenRetirementDate(int(millis), nil)
+	return rawState, nil
+}
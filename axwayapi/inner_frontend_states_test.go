@@ -0,0 +1,215 @@
+package axwayapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"reflect"
+	"testing"
+	"time"
+
+	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// fakeFrontend changes the state of a single frontend the way the API Manager does,
+// refusing the calls it refuses, and recording the others.
+type fakeFrontend struct {
+	client.Frontend
+	// whether publications await approval.
+	approval bool
+	calls    []string
+}
+
+func (f *fakeFrontend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	op := path.Base(r.URL.Path)
+	ok := true
+	switch op {
+	case "publish":
+		ok = f.State == unpublished
+		f.State = published
+		if f.approval {
+			f.State = pending
+		}
+	case "unpublish":
+		ok = f.State != unpublished && (!f.Deprecated || f.Retired)
+		f.State, f.Deprecated, f.Retired, f.RetirementDate = unpublished, false, false, 0
+	case "deprecate":
+		ok = f.State == published && !f.Deprecated
+		f.Deprecated = true
+		if date := r.FormValue("retirementDate"); date != "" {
+			f.RetirementDate = expandRetirementDate(date)
+		}
+	case "undeprecate":
+		ok = f.Deprecated && !f.Retired
+		f.Deprecated, f.RetirementDate = false, 0
+	default:
+		ok = false
+	}
+	if !ok {
+		http.Error(w, op+" refused", http.StatusConflict)
+		return
+	}
+	f.calls = append(f.calls, op)
+	json.NewEncoder(w).Encode(f.Frontend)
+}
+
+func (f *fakeFrontend) client(t *testing.T) *client.Client {
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	c, err := client.NewClient(server.URL, "", "", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+var (
+	retirement = time.Date(2030, 6, 30, 0, 0, 0, 0, time.UTC)
+	// the state of the frontends on the server, by name.
+	serverStates = map[string]client.Frontend{
+		unpublished: {State: unpublished},
+		pending:     {State: pending},
+		published:   {State: published},
+		deprecated:  {State: published, Deprecated: true},
+		"retiring":  {State: published, Deprecated: true, RetirementDate: expandRetirementDate("2030-06-30T00:00:00Z")},
+		"retiring on another date": {State: published, Deprecated: true,
+			RetirementDate: expandRetirementDate("2029-01-01T00:00:00Z")},
+		retired: {State: published, Deprecated: true, Retired: true,
+			RetirementDate: expandRetirementDate("2020-01-01T00:00:00Z")},
+	}
+)
+
+func TestAdaptStates(t *testing.T) {
+	for from, server := range serverStates {
+		for _, to := range []string{unpublished, published, deprecated, retired} {
+			t.Run(from+" to "+to, func(t *testing.T) {
+				f := &fakeFrontend{Frontend: server}
+				f.Id, f.Name = "f", "api"
+				c := f.client(t)
+				diags := adaptStates(c, to, retirement, &f.Frontend)
+
+				switch {
+				case from == retired && to != unpublished && to != retired:
+					if !diags.HasError() || len(f.calls) > 0 {
+						t.Fatalf("got %v, calls %q, want an error and no call", diags, f.calls)
+					}
+				case from == pending && to != unpublished:
+					if !hasWarning(diags) || len(f.calls) > 0 {
+						t.Fatalf("got %v, calls %q, want a warning and no call", diags, f.calls)
+					}
+				default:
+					if diags.HasError() {
+						t.Fatal(diags)
+					}
+					if got := frontendState(&f.Frontend, to, retirement); got != to {
+						t.Fatalf("got %s after %q, want %s", got, f.calls, to)
+					}
+					// a change of the date alone is made by unpublishing first (see unpublishNeededFor).
+					if (to == deprecated || to == retired) && len(f.calls) > 0 {
+						if got := retirementDateOf(&f.Frontend); !got.Equal(retirement) {
+							t.Errorf("got retirement on %v, want %v", got, retirement)
+						}
+					}
+					// once there, nothing is left to do.
+					calls := f.calls
+					if diags = adaptStates(c, to, retirement, &f.Frontend); diags.HasError() || len(f.calls) > len(calls) {
+						t.Errorf("got %v, calls %q, want nothing more than %q", diags, f.calls, calls)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestAdaptStatesAwaitingApproval(t *testing.T) {
+	for _, to := range []string{published, deprecated, retired} {
+		t.Run(to, func(t *testing.T) {
+			f := &fakeFrontend{Frontend: client.Frontend{Id: "f", State: unpublished}, approval: true}
+			diags := adaptStates(f.client(t), to, retirement, &f.Frontend)
+			if diags.HasError() || !hasWarning(diags) {
+				t.Errorf("got %v, want a warning", diags)
+			}
+			if want := []string{"publish"}; !reflect.DeepEqual(f.calls, want) || f.State != pending {
+				t.Errorf("got %s after %q, want pending after %q", f.State, f.calls, want)
+			}
+		})
+	}
+}
+
+func TestRetireWithoutDate(t *testing.T) {
+	f := &fakeFrontend{Frontend: client.Frontend{Id: "f", State: published}}
+	before := time.Now().Add(-time.Second)
+	if diags := adaptStates(f.client(t), retired, time.Time{}, &f.Frontend); diags.HasError() {
+		t.Fatal(diags)
+	}
+	// retired at once, the server telling it only later.
+	if date := retirementDateOf(&f.Frontend); date.Before(before) || date.After(time.Now()) {
+		t.Errorf("got retirement on %v, want now", date)
+	}
+	if got := frontendState(&f.Frontend, retired, time.Time{}); got != retired {
+		t.Errorf("got %s, want %s", got, retired)
+	}
+}
+
+func TestReadFrontendState(t *testing.T) {
+	for _, tt := range []struct {
+		server         string
+		state, date    string
+		want, wantDate string
+	}{
+		{"retiring", retired, "2030-06-30T02:00:00+02:00", retired, "2030-06-30T02:00:00+02:00"},
+		{"retiring", retired, "", retired, "2030-06-30T00:00:00Z"},
+		{"retiring", deprecated, "2030-06-30T00:00:00Z", deprecated, "2030-06-30T00:00:00Z"},
+		{"retiring on another date", retired, "2030-06-30T00:00:00Z", deprecated, "2029-01-01T00:00:00Z"},
+		{retired, deprecated, "", retired, "2020-01-01T00:00:00Z"},
+		{pending, published, "", pending, ""},
+	} {
+		t.Run(tt.server+" as "+tt.state, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, *TFFrontendSchema, map[string]interface{}{
+				"name": "api", "state": tt.state, "retirement_date": tt.date,
+			})
+			server := serverStates[tt.server]
+			flattenFrontend(&server, d)
+			if got, date := d.Get("state"), d.Get("retirement_date"); got != tt.want || date != tt.wantDate {
+				t.Errorf("got %s on %q, want %s on %q", got, date, tt.want, tt.wantDate)
+			}
+		})
+	}
+}
+
+func hasWarning(diags diag.Diagnostics) bool {
+	for _, d := range diags {
+		if d.Severity == diag.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+func TestUpgradeRetirementDate(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		v0   interface{}
+		want string
+	}{
+		{"none", nil, ""},
+		{"zero", float64(0), ""},
+		{"millis", float64(1688083200000), "2023-06-30T00:00:00Z"},
+		{"json number", json.Number("1688083200000"), "2023-06-30T00:00:00Z"},
+		{"already upgraded", "2023-06-30T02:00:00+02:00", "2023-06-30T02:00:00+02:00"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := upgradeRetirementDate(context.Background(), map[string]interface{}{"retirement_date": tt.v0}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := state["retirement_date"]; got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
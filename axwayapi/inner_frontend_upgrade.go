@@ -0,0 +1,81 @@
+package axwayapi
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// The upgrade of a frontend from an older one, usually of a previous version of the backend.
+// The API Manager moves the applications, with their quotas, from the old frontend
+// to the new one in a single operation, so they cannot be migrated separately.
+var TFFrontendUpgrade = resource(schemaMap{
+	"frontend_id": desc(required(_string()), "The id of the frontend to upgrade from"),
+	"migrate": desc(optional(_bool(), true),
+		"Move the applications granted on the old frontend, and their quotas, to this one"),
+	"deprecate": desc(optional(_bool(), false), "Deprecate the old frontend"),
+	"retire":    desc(optional(_bool(), false), "Retire the old frontend on 'retirement_date', which is then required"),
+	"retirement_date": desc(optional(_string(validRFC3339)),
+		`The retirement date of the old frontend, in RFC 3339.
+		Its consumers are cut off on this date: give them the time to move to the new frontend.`),
+})
+
+// checkUpgrade requires a retirement date to retire the old frontend, which would
+// otherwise be retired, cutting off its consumers, as soon as the upgrade is done.
+func checkUpgrade(upgrades cty.Value) error {
+	if upgrades.IsNull() || !upgrades.IsKnown() {
+		return nil
+	}
+	for it := upgrades.ElementIterator(); it.Next(); {
+		_, u := it.Element()
+		if u.IsNull() || !u.IsKnown() {
+			continue
+		}
+		retire, date := u.GetAttr("retire"), u.GetAttr("retirement_date")
+		if retire.IsKnown() && !retire.IsNull() && retire.True() && date.IsNull() {
+			return fmt.Errorf("retiring the old frontend requires a 'retirement_date' in 'upgrade_from'")
+		}
+	}
+	return nil
+}
+
+// syncUpgrade upgrades the frontend from the one given in 'upgrade_from', once:
+// on creation, or when another frontend is given.
+func syncUpgrade(c *client.Client, d *schema.ResourceData, frontend *client.Frontend) (diags diag.Diagnostics) {
+	if !d.IsNewResource() && !d.HasChange("upgrade_from.0.frontend_id") {
+		return diags
+	}
+	v, ok := d.GetOk("upgrade_from")
+	if !ok {
+		return diags
+	}
+	u := v.([]interface{})[0].(map[string]interface{})
+	oldId := u["frontend_id"].(string)
+	retire := u["retire"].(bool)
+	// required to retire (see checkUpgrade).
+	date, _ := time.Parse(time.RFC3339, u["retirement_date"].(string))
+
+	var err error
+	if u["migrate"].(bool) {
+		form := url.Values{}
+		form.Set("upgradeApiId", frontend.Id)
+		form.Set("deprecate", strconv.FormatBool(u["deprecate"].(bool) || retire))
+		form.Set("retire", strconv.FormatBool(retire))
+		if !date.IsZero() {
+			form.Set("retirementDate", date.UTC().Format(time.RFC3339))
+		}
+		err = restPostForm(c, nil, fmt.Sprintf("proxies/upgrade/%s", oldId), form)
+	} else if u["deprecate"].(bool) || retire {
+		err = deprecateFrontend(c, &client.Frontend{Id: oldId}, date)
+	}
+	if err != nil {
+		diags = append(diags, diag.Errorf("upgrade of api %s from %s failed: %v", frontend.Name, oldId, err)...)
+	}
+	return diags
+}
@@ -0,0 +1,237 @@
+package axwayapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/ioutil"
+	"log"
+	"net/http"
+
+	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// The image of an organization, a user, an application or a frontend.
+// Its content is never kept in the state, only its hash, which is computed on plan,
+// and the hash of the image the server saved once uploaded, which the one found
+// on the server is compared with: the server may encode the image again.
+var imageSources = []string{"image.0.path", "image.0.base64", "image.0.url"}
+
+var TFImage = resource(schemaMap{
+	"path":   desc(exactlyOneOfResource(_string(), imageSources...), "The file of the image"),
+	"base64": desc(exactlyOneOfResource(_hashedString(), imageSources...), "The content of the image, base64 encoded"),
+	"url":    desc(exactlyOneOfResource(_string(), imageSources...), "The url the image is read from"),
+})
+
+// The image formats the API Manager accepts, by content type.
+var imageFormats = map[string]string{
+	"image/png":  "png",
+	"image/jpeg": "jpeg",
+	"image/gif":  "gif",
+}
+
+func _image() *schema.Schema {
+	return desc(optional(_listMax(1, TFImage)),
+		"A PNG, JPEG or GIF image. Its format is only detected from its content: the image is not converted")
+}
+
+func _imageHash() *schema.Schema {
+	return desc(readonly(_string()), "The hash of the pixels of the image")
+}
+
+func _imageSaved() *schema.Schema {
+	return desc(readonly(_string()), "The hash of the pixels of the image as saved by the server, once uploaded")
+}
+
+// _imageJpg is the former way to give an image, kept for compatibility.
+func _imageJpg(s *schema.Schema) *schema.Schema {
+	s.Deprecated = "use the 'image' block instead"
+	s.ConflictsWith = []string{"image"}
+	return s
+}
+
+// imageContent is the content of an image with its content type.
+type imageContent struct {
+	content     []byte
+	contentType string
+}
+
+// hash hashes the pixels of the image, so that an image encoded again by the server
+// (e.g. compressed otherwise, or in another format) keeps the same hash.
+// A lossy encoding (JPEG) does change it. An image which cannot be decoded has its content hashed.
+func (i *imageContent) hash() string {
+	img, _, err := image.Decode(bytes.NewReader(i.content))
+	if err != nil {
+		return fmt.Sprintf("%x", sha256.Sum256(i.content))
+	}
+	h := sha256.New()
+	b := img.Bounds()
+	binary.Write(h, binary.BigEndian, [2]int64{int64(b.Dx()), int64(b.Dy())})
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBA64Model.Convert(img.At(x, y)).(color.NRGBA64)
+			binary.Write(h, binary.BigEndian, [4]uint16{c.R, c.G, c.B, c.A})
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// loadImage reads the image from its source, checking its format.
+func loadImage(ctx context.Context, m interface{}, path, b64, url string) (*imageContent, error) {
+	var content []byte
+	var err error
+	switch {
+	case path != "":
+		content, err = ioutil.ReadFile(path)
+	case b64 != "":
+		content, err = base64.StdEncoding.DecodeString(b64)
+	case url != "":
+		content, err = download(ctx, m, url)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the image: %w", err)
+	}
+	contentType := http.DetectContentType(content)
+	if _, ok := imageFormats[contentType]; !ok {
+		return nil, fmt.Errorf("the image is not a PNG, JPEG or GIF image but %s", contentType)
+	}
+	return &imageContent{content, contentType}, nil
+}
+
+// configuredImage loads the image of the 'image' block, if any.
+func configuredImage(ctx context.Context, m interface{}, d *schema.ResourceData) (*imageContent, error) {
+	l := d.Get("image").([]interface{})
+	if len(l) == 0 || l[0] == nil {
+		return nil, nil
+	}
+	a := l[0].(map[string]interface{})
+	return loadImage(ctx, m, a["path"].(string), a["base64"].(string), a["url"].(string))
+}
+
+// customizeImage plans the hash of the configured image, so that a changed file
+// or an image changed on the server is uploaded again.
+func customizeImage(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	v := d.GetRawConfig().GetAttr("image")
+	if !v.IsKnown() {
+		return d.SetNewComputed("image_hash")
+	}
+	if v.IsNull() || v.LengthInt() == 0 {
+		return nil
+	}
+	if !v.IsWhollyKnown() {
+		return d.SetNewComputed("image_hash")
+	}
+	a := v.Index(cty.NumberIntVal(0))
+	str := func(k string) string {
+		if s := a.GetAttr(k); !s.IsNull() {
+			return s.AsString()
+		}
+		return ""
+	}
+	img, err := loadImage(ctx, m, str("path"), str("base64"), str("url"))
+	if err != nil {
+		return err
+	}
+	if hash := img.hash(); hash != d.Get("image_hash") {
+		return d.SetNew("image_hash", hash)
+	}
+	return nil
+}
+
+// imagePath gives the url of the image of the object.
+func imagePath(object client.WithId) string {
+	var path string
+	switch object.(type) {
+	case *client.Frontend:
+		path = "proxies"
+	case *client.Application:
+		path = "applications"
+	case *client.User:
+		path = "users"
+	case *client.Org:
+		path = "organizations"
+	}
+	return fmt.Sprintf("%s/%s/image", path, object.GetId())
+}
+
+func uploadImage(c *client.Client, object client.WithId, img *imageContent) error {
+	file := restFile{"file", "image." + imageFormats[img.contentType], img.contentType, img.content}
+	_, err := restPostParts(c, imagePath(object)+"/", nil, file)
+	return err
+}
+
+// savedImageHash gives the hash of the image found on the server.
+func savedImageHash(c *client.Client, object client.WithId) (string, error) {
+	b, err := restDo(c, "GET", imagePath(object), nil, "")
+	if err != nil {
+		return "", err
+	}
+	return (&imageContent{content: b}).hash(), nil
+}
+
+// syncImage uploads the image when it changed, from 'image_jpg' or from the 'image' block.
+func syncImage(ctx context.Context, m interface{}, d *schema.ResourceData, object client.WithId, c *client.Client) (diags diag.Diagnostics) {
+	// image is a special case.
+	if d.HasChange("image_jpg") {
+		err := c.UpdateImageFor(object, d.Get("image_jpg").(string))
+		if nil != err {
+			diags = warn(diags, "updating image for %T %s failed: %v", object, object.GetId(), err)
+		}
+	}
+	if d.HasChange("image_hash") {
+		img, err := configuredImage(ctx, m, d)
+		if err == nil && img != nil {
+			err = uploadImage(c, object, img)
+		}
+		if nil != err {
+			// to be uploaded again on the next apply.
+			old, _ := d.GetChange("image_hash")
+			d.Set("image_hash", old)
+			diags = warn(diags, "updating image for %T %s failed: %v", object, object.GetId(), err)
+			return diags
+		}
+		// the server may have encoded it again (e.g. a JPEG compressed otherwise).
+		saved := ""
+		if img != nil {
+			if saved, err = savedImageHash(c, object); err != nil {
+				log.Printf("[WARN] cannot read the image of %T %s back, its changes are not detected: %v", object, object.GetId(), err)
+			}
+		}
+		d.Set("image_saved", saved)
+	}
+	return diags
+}
+
+// readImage compares the image found on the server with the one it saved on the upload,
+// or with the configured one for the images uploaded before it was kept.
+// A different image makes its hash change, for it to be uploaded again on the next apply.
+func readImage(c *client.Client, d *schema.ResourceData, object client.WithId) {
+	if len(d.Get("image").([]interface{})) == 0 {
+		return
+	}
+	hash, err := savedImageHash(c, object)
+	if err != nil {
+		log.Printf("[WARN] cannot read the image of %T %s, its changes are not detected: %v", object, object.GetId(), err)
+		return
+	}
+	known := d.Get("image_saved").(string)
+	if known == "" {
+		known = d.Get("image_hash").(string)
+	}
+	if hash != known {
+		log.Printf("[INFO] the image of %T %s differs from the known one", object, object.GetId())
+		d.Set("image_hash", hash)
+		d.Set("image_saved", "")
+	}
+}
@@ -0,0 +1,126 @@
+package axwayapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// reencodingServer keeps the images uploaded to it as JPEG, as a server may do.
+type reencodingServer struct {
+	saved []byte
+}
+
+func (s *reencodingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		b, _ := ioutil.ReadAll(f)
+		img, _, err := image.Decode(bytes.NewReader(b))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var buf bytes.Buffer
+		jpeg.Encode(&buf, img, &jpeg.Options{Quality: 50})
+		s.saved = buf.Bytes()
+	case "GET":
+		w.Write(s.saved)
+	}
+}
+
+func gradient(t *testing.T, shift int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			img.Set(x, y, color.NRGBA{uint8(x * 16), uint8(y * 16), uint8(x*y + shift), 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageSavedByServer(t *testing.T) {
+	s := &reencodingServer{}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	c, err := client.NewClient(server.URL, "", "", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := gradient(t, 0)
+	configured := (&imageContent{content: content}).hash()
+	org := &client.Org{Id: "o"}
+	d := schema.TestResourceDataRaw(t, TFOrgSchema, map[string]interface{}{
+		"name": "org", "enabled": true,
+		"image": []interface{}{map[string]interface{}{"base64": base64.StdEncoding.EncodeToString(content)}},
+	})
+	img, err := configuredImage(context.Background(), nil, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = uploadImage(c, org, img); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := savedImageHash(c, org)
+	if err != nil || saved == configured {
+		t.Fatalf("got %q saved, %v, want the hash of the JPEG image", saved, err)
+	}
+	// as syncImage keeps them.
+	d.Set("image_hash", configured)
+	d.Set("image_saved", saved)
+	// the image the server encoded again is not uploaded again.
+	readImage(c, d, org)
+	if got := d.Get("image_hash"); got != configured {
+		t.Errorf("got hash %s, want %s", got, configured)
+	}
+
+	// an image changed on the server is.
+	s.saved = gradient(t, 1)
+	readImage(c, d, org)
+	if got := d.Get("image_hash"); got == configured {
+		t.Errorf("got hash %s, want another one", got)
+	}
+	if got := d.Get("image_saved"); got != "" {
+		t.Errorf("got %q saved, want none", got)
+	}
+}
+
+func TestReadImageUploadedBefore(t *testing.T) {
+	s := &reencodingServer{saved: gradient(t, 0)}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	c, err := client.NewClient(server.URL, "", "", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configured := (&imageContent{content: s.saved}).hash()
+	d := schema.TestResourceDataRaw(t, TFOrgSchema, map[string]interface{}{
+		"name": "org", "enabled": true,
+		"image": []interface{}{map[string]interface{}{"path": "image.png"}},
+	})
+	// no saved hash: the configured one is compared.
+	d.Set("image_hash", configured)
+	readImage(c, d, &client.Org{Id: "o"})
+	if got := d.Get("image_hash"); got != configured {
+		t.Errorf("got hash %s, want %s", got, configured)
+	}
+}
@@ -0,0 +1,182 @@
+package axwayapi
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+)
+
+// The types of policies the API Manager knows of.
+var policyTypes = []string{"request", "response", "routing", "faulthandler", "global", "authentication"}
+
+// A policy of the gateway. Its id is the key (an ESPK) referencing it,
+// e.g. "<key type='CircuitContainer'><id field='name' value='Policies'/>...</key>".
+type Policy struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+}
+
+func listPolicies(c *client.Client, policyType string) (ret []Policy, err error) {
+	err = restGet(c, &ret, "policies?type="+url.QueryEscape(policyType))
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// The OAuth token stores are referenced the same way as the policies.
+func listTokenStores(c *client.Client) (ret []Policy, err error) {
+	err = restGet(c, &ret, "tokenstores")
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// isPolicyKey tells a key from the name of a policy.
+func isPolicyKey(ref string) bool {
+	return ref == "" || strings.HasPrefix(strings.TrimSpace(ref), "<key")
+}
+
+// policyIndex resolves the policies by name, listing each type of policies
+// at most once for the duration of a single operation.
+type policyIndex struct {
+	c        *client.Client
+	policies map[string][]Policy
+}
+
+func newPolicyIndex(c *client.Client) *policyIndex {
+	return &policyIndex{c: c, policies: map[string][]Policy{}}
+}
+
+func (idx *policyIndex) list(policyType string) ([]Policy, error) {
+	if p, ok := idx.policies[policyType]; ok {
+		return p, nil
+	}
+	p, err := listPolicies(idx.c, policyType)
+	if err != nil {
+		return nil, err
+	}
+	idx.policies[policyType] = p
+	return p, nil
+}
+
+// resolve gives the key of the policy designated by ref, either a key or a name.
+func (idx *policyIndex) resolve(policyType, ref string) (string, error) {
+	if isPolicyKey(ref) {
+		return ref, nil
+	}
+	policies, err := idx.list(policyType)
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, len(policies))
+	for i, p := range policies {
+		if p.Name == ref {
+			return p.Id, nil
+		}
+		names[i] = p.Name
+	}
+	sort.Strings(names)
+	return "", fmt.Errorf("no %s policy named '%s', expecting one of %q", policyType, ref, names)
+}
+
+// nameOf gives back the name found in prior if it designates the given key,
+// so that referencing a policy by name does not cause a perpetual diff.
+// Otherwise the key itself is returned.
+func (idx *policyIndex) nameOf(policyType, key string, prior interface{}) string {
+	name, ok := prior.(string)
+	if !ok || isPolicyKey(name) {
+		return key
+	}
+	if k, err := idx.resolve(policyType, name); err == nil && k == key {
+		return name
+	}
+	return key
+}
+
+// A reference to a policy, within a larger object.
+type policyRef struct {
+	policyType string
+	ref        *string
+}
+
+// The policies of an outbound profile, by attribute.
+func outboundPolicies(p *client.OutboundProfile) map[string]policyRef {
+	return map[string]policyRef{
+		"request_policy":       {"request", &p.RequestPolicy},
+		"response_policy":      {"response", &p.ResponsePolicy},
+		"route_policy":         {"routing", &p.RoutePolicy},
+		"fault_handler_policy": {"faulthandler", &p.FaultHandlerPolicy},
+	}
+}
+
+// The global policies of the configuration, by attribute.
+func configPolicies(cfg *client.Config) map[string]policyRef {
+	return map[string]policyRef{
+		"global_request_policy":       {"global", &cfg.GlobalRequestPolicy},
+		"global_response_policy":      {"global", &cfg.GlobalResponsePolicy},
+		"global_fault_handler_policy": {"faulthandler", &cfg.GlobalFaultHandlerPolicy},
+	}
+}
+
+func resolvePolicies(idx *policyIndex, policies map[string]policyRef) error {
+	for _, policy := range policies {
+		key, err := idx.resolve(policy.policyType, *policy.ref)
+		if err != nil {
+			return err
+		}
+		*policy.ref = key
+	}
+	return nil
+}
+
+// namePolicies puts back the names of the policies as found in prior, by attribute.
+func namePolicies(idx *policyIndex, policies map[string]policyRef, prior func(attr string) interface{}) {
+	for attr, policy := range policies {
+		*policy.ref = idx.nameOf(policy.policyType, *policy.ref, prior(attr))
+	}
+}
+
+// resolveOutboundProfiles replaces the names of the policies and of the
+// backend methods in the outbound profiles by the keys and ids the server expects.
+func resolveOutboundProfiles(c *client.Client, profiles map[string]client.OutboundProfile) error {
+	idx := newPolicyIndex(c)
+	methods := backendMethodIndexes{}
+	for name, p := range profiles {
+		if err := resolvePolicies(idx, outboundPolicies(&p)); err != nil {
+			return fmt.Errorf("outbound profile '%s': %w", name, err)
+		}
+		if err := methods.resolve(c, p.ApiId, &p.ApiMethodId); err != nil {
+			return fmt.Errorf("outbound profile '%s': %w", name, err)
+		}
+		profiles[name] = p
+	}
+	return nil
+}
+
+// nameOutboundProfiles puts back the names of the policies and of the
+// backend methods as found in the prior outbound profiles.
+func nameOutboundProfiles(c *client.Client, profiles map[string]client.OutboundProfile, prior interface{}) {
+	priors := map[string]map[string]interface{}{}
+	if l, ok := prior.([]interface{}); ok {
+		for _, e := range l {
+			if a, ok := e.(map[string]interface{}); ok {
+				priors[a["name"].(string)] = a
+			}
+		}
+	}
+	idx := newPolicyIndex(c)
+	methods := backendMethodIndexes{}
+	for name, p := range profiles {
+		namePolicies(idx, outboundPolicies(&p), func(attr string) interface{} {
+			return priors[name][attr]
+		})
+		p.ApiMethodId = methods.nameOf(c, p.ApiId, p.ApiMethodId, priors[name]["api_method_id"])
+		profiles[name] = p
+	}
+}
@@ -29,30 +29,30 @@ func flattenDevices(c []client.Device) []flattenMap {
 	r := make([]flattenMap, len(c))
 	for i, a := range c {
 		r[i] = flattenMap{
-			"name":  a.Name,  //required(inOut(_string()))
-			"order": a.Order, //required(inOut(_int()))
+			"name": a.Name, //required(inOut(_string()))
+			// "order" is the position of the device in the list.
 		}
 		switch a.Type {
 		case "apiKey":
-			r[i]["api_key"] = flattenMap{
+			r[i]["api_key"] = []flattenMap{{
 				"remove_credentials_on_success": a.Properties["removeCredentialsOnSuccess"] == "true",
 				"api_key_field_name":            a.Properties["apiKeyFieldName"],
 				"take_from":                     a.Properties["takeFrom"],
-			}
+			}}
 		case "awsHeader":
-			r[i]["aws_header"] = flattenMap{
+			r[i]["aws_header"] = []flattenMap{{
 				"remove_credentials_on_success": a.Properties["removeCredentialsOnSuccess"] == "true",
-			}
+			}}
 		case "awsQuery":
-			r[i]["aws_query"] = flattenMap{
+			r[i]["aws_query"] = []flattenMap{{
 				"remove_credentials_on_success": a.Properties["removeCredentialsOnSuccess"] == "true",
 				"api_key_field_name":            a.Properties["apiKeyFieldName"],
-			}
+			}}
 		case "basic":
-			r[i]["basic"] = flattenMap{
+			r[i]["basic"] = []flattenMap{{
 				"remove_credentials_on_success": a.Properties["removeCredentialsOnSuccess"] == "true",
 				"realm":                         a.Properties["realm"], //required(inOut(_string())),
-			}
+			}}
 		case "oauth":
 			oauth := flattenMap{
 				"remove_credentials_on_success":      a.Properties["removeCredentialsOnSuccess"] == "true",
@@ -65,39 +65,68 @@ func flattenDevices(c []client.Device) []flattenMap {
 			}
 			implicit, _ := strconv.ParseBool(a.Properties["implicitGrantEnabled"].(string))
 			if implicit {
-				oauth["implicit_grant"] = flattenMap{
+				oauth["implicit_grant"] = []flattenMap{{
 					"login_endpoint_url": a.Properties["implicitGrantLoginEndpointUrl"],
 					"login_token_name":   a.Properties["implicitGrantLoginTokenName"],
-				}
+				}}
 			}
 			authCode, _ := strconv.ParseBool(a.Properties["authCodeGrantTypeEnabled"].(string))
 			if authCode {
-				oauth["implicit_grant"] = flattenMap{
+				oauth["auth_code_grant"] = []flattenMap{{
 					"request_endpoint_url":      a.Properties["authCodeGrantTypeRequestEndpointUrl"],
 					"request_client_id_name":    a.Properties["authCodeGrantTypeRequestClientIdName"],
 					"request_secret_name":       a.Properties["authCodeGrantTypeRequestSecretName"],
 					"token_endpoint_url":        a.Properties["authCodeGrantTypeTokenEndpointUrl"],
 					"token_endpoint_token_name": a.Properties["authCodeGrantTypeTokenEndpointTokenName"],
-				}
+				}}
 			}
 			clientCred, _ := strconv.ParseBool(a.Properties["clientCredentialsGrantTypeEnabled"].(string))
 			if clientCred {
-				oauth["implicit_grant"] = flattenMap{
+				oauth["client_credentials_grant"] = []flattenMap{{
 					"token_endpoint_url": a.Properties["clientCredentialsGrantTypeTokenEndpointUrl"],
 					"token_name":         a.Properties["clientCredentialsGrantTypeTokenName"],
-				}
+				}}
 			}
-			r[i]["oauth"] = oauth
+			r[i]["oauth"] = []flattenMap{oauth}
 		case "twoWaySSL":
-			r[i]["two_ways_ssl"] = flattenMap{
+			r[i]["two_ways_ssl"] = []flattenMap{{
 				"remove_credentials_on_success": a.Properties["removeCredentialsOnSuccess"] == "true",
 				"api_key_field_name":            a.Properties["apiKeyFieldName"],
-			}
+			}}
 		case "passThrough":
-			r[i]["passthrough"] = flattenMap{
+			r[i]["passthrough"] = []flattenMap{{
 				"remove_credentials_on_success": a.Properties["removeCredentialsOnSuccess"] == "true",
 				"subject_id_field_name":         a.Properties["subjectIdFieldName"],
+			}}
+		case "oauthExternal":
+			r[i]["oauth_external"] = []flattenMap{{
+				"remove_credentials_on_success":      a.Properties["removeCredentialsOnSuccess"] == "true",
+				"token_info_policy":                  a.Properties["tokenStore"],
+				"access_token_location":              a.Properties["accessTokenLocation"],
+				"authorization_header_prefix":        a.Properties["authorizationHeaderPrefix"],
+				"access_token_location_query_string": a.Properties["accessTokenLocationQueryString"],
+				"scopes_must_match":                  a.Properties["scopesMustMatch"],
+				"scopes":                             a.Properties["scopes"],
+				"use_client_registry":                a.Properties["useClientRegistry"] == "true",
+				"subject_selector":                   a.Properties["subjectSelector"],
+			}}
+		case "authPolicy":
+			r[i]["auth_policy"] = []flattenMap{{
+				"remove_credentials_on_success": a.Properties["removeCredentialsOnSuccess"] == "true",
+				"authentication_policy":         a.Properties["authenticationPolicy"],
+				"use_client_registry":           a.Properties["useClientRegistry"] == "true",
+				"subject_selector":              a.Properties["subjectSelector"],
+			}}
+		default:
+			// Keep whatever we do not know of as is.
+			props := make(map[string]interface{}, len(a.Properties))
+			for k, v := range a.Properties {
+				props[k] = fmt.Sprint(v)
 			}
+			r[i]["custom"] = []flattenMap{{
+				"type":       a.Type,
+				"properties": props,
+			}}
 		}
 	}
 	return r
@@ -114,6 +143,12 @@ var TFDevice = &schema.Resource{
 		"oauth":        optional(_singleton(TFOAuthProperties)),       // exactlyOneOfResource(_singleton(TFOAuthProperties), excl...),
 		"two_ways_ssl": optional(_singleton(TFTwoWaysSslProperties)),  // exactlyOneOfResource(_singleton(TFTwoWaysSslProperties), excl...),
 		"passthrough":  optional(_singleton(TFPassthroughProperties)), // exactlyOneOfResource(_singleton(TFPassthroughProperties), excl...),
+		"oauth_external": desc(optional(_singleton(TFOAuthExternalProperties)),
+			"OAuth, with the tokens validated by an external authorization server"),
+		"auth_policy": desc(optional(_singleton(TFAuthPolicyProperties)),
+			"Invoke policy: the authentication is delegated to a policy of the gateway"),
+		"custom": desc(optional(_singleton(TFCustomDeviceProperties)),
+			"Any other type of device, with its properties as expected by the API Manager"),
 	},
 }
 
@@ -240,10 +275,46 @@ func expandDevices(v interface{}) []client.Device {
 			}
 			r[i].Properties = props
 		}
+		if v, ok := a["oauth_external"]; ok && len(v.([]interface{})) > 0 {
+			nb = nb + 1
+			params = v.([]interface{})[0].(map[string]interface{})
+			r[i].Type = "oauthExternal"
+			r[i].Properties = flattenMap{
+				"tokenStore":                     params["token_info_policy"],
+				"accessTokenLocation":            params["access_token_location"],
+				"authorizationHeaderPrefix":      params["authorization_header_prefix"],
+				"accessTokenLocationQueryString": params["access_token_location_query_string"],
+				"scopesMustMatch":                params["scopes_must_match"],
+				"scopes":                         params["scopes"],
+				"useClientRegistry":              params["use_client_registry"],
+				"subjectSelector":                params["subject_selector"],
+			}
+		}
+		if v, ok := a["auth_policy"]; ok && len(v.([]interface{})) > 0 {
+			nb = nb + 1
+			params = v.([]interface{})[0].(map[string]interface{})
+			r[i].Type = "authPolicy"
+			r[i].Properties = flattenMap{
+				"authenticationPolicy": params["authentication_policy"],
+				"useClientRegistry":    params["use_client_registry"],
+				"subjectSelector":      params["subject_selector"],
+			}
+		}
+		if v, ok := a["custom"]; ok && len(v.([]interface{})) > 0 {
+			nb = nb + 1
+			params = v.([]interface{})[0].(map[string]interface{})
+			r[i].Type = params["type"].(string)
+			r[i].Properties = flattenMap{}
+			for k, v := range params["properties"].(map[string]interface{}) {
+				r[i].Properties[k] = v
+			}
+		}
 		if nb != 1 {
-			panic(fmt.Errorf("exactly one of 'api_key', 'aws_header', 'aws_query', 'basic', 'oauth', 'two_ways_ssl', 'passthrough' can be defined for a device, found %d here", nb))
+			panic(fmt.Errorf("exactly one of 'api_key', 'aws_header', 'aws_query', 'basic', 'oauth', 'two_ways_ssl', 'passthrough', 'oauth_external', 'auth_policy', 'custom' can be defined for a device, found %d here", nb))
+		}
+		if _, custom := params["properties"]; !custom {
+			r[i].Properties["removeCredentialsOnSuccess"] = params["remove_credentials_on_success"]
 		}
-		r[i].Properties["removeCredentialsOnSuccess"] = params["remove_credentials_on_success"]
 	}
 	return r
 }
@@ -316,3 +387,29 @@ var TFOAuthClientCredentialsGrant = resource(schemaMap{
 	"token_endpoint_url": required(inOut(_string())), // "https://localhost:8089/api/oauth/token",
 	"token_name":         required(inOut(_string())), // "access_token",
 })
+
+var TFOAuthExternalProperties = resource(schemaMap{
+	"remove_credentials_on_success": optional(inOut(_bool()), true),
+	"token_info_policy": desc(required(inOut(_string())),
+		"The key of the policy validating the tokens, see the axwayapi_authentication_policy data source"),
+	"access_token_location":              required(inOut(_string(oneOf("HEADER", "QUERYSTRING")))),
+	"authorization_header_prefix":        optional(inOut(_string()), "Bearer"),
+	"access_token_location_query_string": optional(inOut(_string())),
+	"scopes_must_match":                  required(inOut(_string(oneOf("Any", "All")))),
+	"scopes":                             required(inOut(_string())),
+	"use_client_registry":                optional(inOut(_bool()), true),
+	"subject_selector":                   optional(inOut(_string()), "${oauth.token.client_id}"),
+})
+
+var TFAuthPolicyProperties = resource(schemaMap{
+	"remove_credentials_on_success": optional(inOut(_bool()), true),
+	"authentication_policy": desc(required(inOut(_string())),
+		"The key of the policy, see the axwayapi_authentication_policy data source"),
+	"use_client_registry": optional(inOut(_bool()), true),
+	"subject_selector":    optional(inOut(_string()), "${authentication.subject.id}"),
+})
+
+var TFCustomDeviceProperties = resource(schemaMap{
+	"type":       desc(required(_string()), "The type of device, as known to the API Manager"),
+	"properties": desc(optional(_map(schema.TypeString)), "The properties of the device, all given as strings"),
+})
@@ -0,0 +1,319 @@
+package axwayapi
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// The types of the specs a backend is imported from, as named by the API Manager.
+const (
+	swaggerSpec = "swagger"
+	oas3Spec    = "oas30"
+	wsdlSpec    = "wsdl"
+)
+
+var specTypes = []string{swaggerSpec, oas3Spec, wsdlSpec}
+
+// A spec, hashed once normalized: reformatting a YAML spec makes no difference.
+func _specString() *schema.Schema {
+	return _apply(hashSpec, _string())
+}
+
+func hashSpec(v interface{}) string {
+	if s, ok := v.(string); ok {
+		if n, err := normalizeSpec(s); err == nil {
+			return _hash(n)
+		}
+	}
+	return _hash(v)
+}
+
+func isXML(spec string) bool {
+	return strings.HasPrefix(strings.TrimLeft(spec, "\ufeff \t\r\n"), "<")
+}
+
+// normalizeSpec gives the spec as it is imported: YAML is turned into JSON.
+// JSON and XML specs are left as they are.
+func normalizeSpec(spec string) (string, error) {
+	if isXML(spec) || json.Valid([]byte(spec)) {
+		return spec, nil
+	}
+	var doc interface{}
+	if err := yaml.Unmarshal([]byte(spec), &doc); err != nil {
+		return "", fmt.Errorf("the spec is neither JSON, YAML nor XML: %w", err)
+	}
+	if _, ok := doc.(map[string]interface{}); !ok {
+		return "", fmt.Errorf("the spec is not a JSON or YAML object")
+	}
+	b, err := json.Marshal(jsonCompatible(doc))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// canonicalSpec gives the spec in a form its formatting makes no difference to:
+// JSON is encoded again, with its keys sorted. XML is left as it is.
+func canonicalSpec(spec string) (string, error) {
+	n, err := normalizeSpec(spec)
+	if err != nil || isXML(n) {
+		return n, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal([]byte(n), &doc); err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// hashCanonicalSpec hashes the spec in its canonical form, to compare it with the one kept by the server,
+// which may have reformatted it.
+func hashCanonicalSpec(spec string) string {
+	if c, err := canonicalSpec(spec); err == nil {
+		return _hash(c)
+	}
+	return _hash(spec)
+}
+
+// YAML allows keys that are not strings, e.g. the status codes of the responses.
+func jsonCompatible(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = jsonCompatible(e)
+		}
+		return t
+	case map[interface{}]interface{}:
+		r := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			r[fmt.Sprint(k)] = jsonCompatible(e)
+		}
+		return r
+	case []interface{}:
+		for i, e := range t {
+			t[i] = jsonCompatible(e)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+// specType detects the type of a spec, from its content.
+func specType(spec string) (string, error) {
+	if isXML(spec) {
+		if _, err := parseWSDL(spec); err != nil {
+			return "", err
+		}
+		return wsdlSpec, nil
+	}
+	n, err := normalizeSpec(spec)
+	if err != nil {
+		return "", err
+	}
+	doc := struct {
+		Swagger string `json:"swagger"`
+		OpenAPI string `json:"openapi"`
+	}{}
+	if err := json.Unmarshal([]byte(n), &doc); err != nil {
+		return "", err
+	}
+	switch {
+	case strings.HasPrefix(doc.Swagger, "2."):
+		return swaggerSpec, nil
+	case strings.HasPrefix(doc.OpenAPI, "3."):
+		return oas3Spec, nil
+	}
+	return "", fmt.Errorf("cannot tell the type of the spec, neither 'swagger: 2.x' nor 'openapi: 3.x' are found; give its type")
+}
+
+// The verbs under which the operations are found in the paths of a spec.
+var specVerbs = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// specOperations lists the operations of a spec, by "VERB /path".
+// Each is given a fingerprint "<operationId>#<hash>", so that a changed operation
+// shows as such in a plan. The operations of a WSDL are not listed.
+func specOperations(spec string) (map[string]interface{}, error) {
+	r := map[string]interface{}{}
+	if isXML(spec) {
+		return r, nil
+	}
+	spec, err := normalizeSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	doc := struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}{}
+	if err := json.Unmarshal([]byte(spec), &doc); err != nil {
+		return nil, fmt.Errorf("cannot read the operations of the spec: %w", err)
+	}
+	for path, item := range doc.Paths {
+		for _, verb := range specVerbs {
+			raw, ok := item[verb]
+			if !ok {
+				continue
+			}
+			op := struct {
+				OperationId string `json:"operationId"`
+			}{}
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("cannot read operation %s %s of the spec: %w", verb, path, err)
+			}
+			// re-encoding gives a canonical form, as keys get sorted.
+			var canonical interface{}
+			json.Unmarshal(raw, &canonical)
+			b, _ := json.Marshal(canonical)
+			h := sha256.Sum256(b)
+			r[strings.ToUpper(verb)+" "+path] = fmt.Sprintf("%s#%x", op.OperationId, h[:4])
+		}
+	}
+	return r, nil
+}
+
+// What is told by a spec about the api it describes.
+var TFSpecInfo = resource(schemaMap{
+	"title":       readonly(_string()),
+	"version":     readonly(_string()),
+	"description": readonly(_string()),
+	"base_path":   readonly(_string()),
+	"servers":     desc(readonly(_plist(schema.TypeString)), "The urls of the servers, or the addresses of the services of a WSDL"),
+})
+
+type specInfo struct {
+	Title, Version, Description, BasePath string
+	Servers                               []string
+}
+
+func (i *specInfo) flatten() []interface{} {
+	return []interface{}{flattenMap{
+		"title":       i.Title,
+		"version":     i.Version,
+		"description": i.Description,
+		"base_path":   i.BasePath,
+		"servers":     i.Servers,
+	}}
+}
+
+// parseSpec reads and checks a spec, so that a malformed one fails before being imported.
+func parseSpec(spec string) (*specInfo, error) {
+	if isXML(spec) {
+		return parseWSDL(spec)
+	}
+	n, err := normalizeSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	doc := struct {
+		Swagger string `json:"swagger"`
+		OpenAPI string `json:"openapi"`
+		Info    *struct {
+			Title       string `json:"title"`
+			Version     string `json:"version"`
+			Description string `json:"description"`
+		} `json:"info"`
+		Host     string                     `json:"host"`
+		BasePath string                     `json:"basePath"`
+		Schemes  []string                   `json:"schemes"`
+		Servers  []struct{ Url string }     `json:"servers"`
+		Paths    map[string]json.RawMessage `json:"paths"`
+	}{}
+	if err := json.Unmarshal([]byte(n), &doc); err != nil {
+		return nil, fmt.Errorf("the spec is malformed: %w", err)
+	}
+	switch {
+	case doc.Info == nil:
+		return nil, fmt.Errorf("the spec has no 'info'")
+	case doc.Info.Title == "" || doc.Info.Version == "":
+		return nil, fmt.Errorf("the 'info' of the spec must give its 'title' and 'version'")
+	case doc.Paths == nil:
+		return nil, fmt.Errorf("the spec has no 'paths'")
+	}
+	for path := range doc.Paths {
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("the path '%s' of the spec does not start with '/'", path)
+		}
+	}
+	info := &specInfo{
+		Title:       doc.Info.Title,
+		Version:     doc.Info.Version,
+		Description: doc.Info.Description,
+		BasePath:    doc.BasePath,
+		Servers:     []string{},
+	}
+	if doc.Swagger != "" {
+		if doc.BasePath != "" && !strings.HasPrefix(doc.BasePath, "/") {
+			return nil, fmt.Errorf("the basePath '%s' of the spec does not start with '/'", doc.BasePath)
+		}
+		if doc.Host != "" {
+			schemes := doc.Schemes
+			if len(schemes) == 0 {
+				schemes = []string{"https"}
+			}
+			for _, scheme := range schemes {
+				info.Servers = append(info.Servers, scheme+"://"+doc.Host+doc.BasePath)
+			}
+		}
+		return info, nil
+	}
+	for _, server := range doc.Servers {
+		info.Servers = append(info.Servers, server.Url)
+	}
+	// the base path of an OpenAPI 3 spec is the one of its first server.
+	if len(info.Servers) > 0 {
+		if u, err := url.Parse(info.Servers[0]); err == nil {
+			info.BasePath = u.Path
+		}
+	}
+	return info, nil
+}
+
+// parseWSDL checks that the XML is a WSDL, and reads its name and the addresses of its services.
+func parseWSDL(spec string) (*specInfo, error) {
+	info := &specInfo{Servers: []string{}}
+	dec := xml.NewDecoder(strings.NewReader(spec))
+	root := true
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("the WSDL is malformed: %w", err)
+		}
+		e, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if root {
+			if e.Name.Local != "definitions" && e.Name.Local != "description" {
+				return nil, fmt.Errorf("the XML spec is not a WSDL, its root is '%s'", e.Name.Local)
+			}
+			root = false
+		}
+		for _, a := range e.Attr {
+			switch {
+			case e.Name.Local == "definitions" && a.Name.Local == "name":
+				info.Title = a.Value
+			case e.Name.Local == "address" && a.Name.Local == "location":
+				info.Servers = append(info.Servers, a.Value)
+			}
+		}
+	}
+	if root {
+		return nil, fmt.Errorf("the WSDL is empty")
+	}
+	return info, nil
+}
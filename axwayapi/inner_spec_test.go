@@ -0,0 +1,251 @@
+package axwayapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	swaggerJSON = `{"swagger": "2.0", "info": {"title": "Pets", "version": "1.0"},
+		"host": "pets.example.com", "basePath": "/v1", "schemes": ["http", "https"],
+		"paths": {"/pets": {"get": {"operationId": "listPets", "responses": {"200": {"description": "ok"}}}}}}`
+	swaggerYAML = `
+swagger: "2.0"
+info:
+  title: Pets
+  version: "1.0"
+host: pets.example.com
+basePath: /v1
+schemes: [http, https]
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        200:
+          description: ok
+`
+	oas3YAML = `
+openapi: 3.0.1
+info:
+  title: Pets
+  version: "2.0"
+  description: All the pets
+servers:
+  - url: https://pets.example.com/api/v2
+  - url: http://localhost:8080/
+paths:
+  /pets/{id}:
+    get:
+      operationId: getPet
+    delete:
+      responses:
+        204:
+          description: gone
+`
+	wsdl11 = `<?xml version="1.0"?>
+<wsdl:definitions name="Pets" xmlns:wsdl="http://schemas.xmlsoap.org/wsdl/" xmlns:soap="http://schemas.xmlsoap.org/wsdl/soap/">
+  <wsdl:service name="PetService">
+    <wsdl:port name="PetPort" binding="PetBinding">
+      <soap:address location="http://pets.example.com/soap"/>
+    </wsdl:port>
+  </wsdl:service>
+</wsdl:definitions>`
+	wsdl20 = `<description xmlns="http://www.w3.org/ns/wsdl"><service name="Pets"/></description>`
+)
+
+func TestNormalizeSpec(t *testing.T) {
+	for _, tt := range []struct {
+		name, spec, want string
+	}{
+		{"json is kept", `{"b": 1,  "a": 2}`, `{"b": 1,  "a": 2}`},
+		{"xml is kept", wsdl20, wsdl20},
+		{"yaml", "b: 1\na: x", `{"a":"x","b":1}`},
+		// the status codes of the responses are not strings in YAML.
+		{"yaml non-string keys", "responses:\n  200: ok\n  true: yes\n  1.5: half", `{"responses":{"1.5":"half","200":"ok","true":"yes"}}`},
+		{"yaml lists", "a:\n  - 404: x\n  - y", `{"a":[{"404":"x"},"y"]}`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeSpec(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+	for _, spec := range []string{"just a string", "- a list", "a: [unclosed"} {
+		if got, err := normalizeSpec(spec); err == nil {
+			t.Errorf("%q: got %s, want an error", spec, got)
+		}
+	}
+}
+
+func TestHashCanonicalSpec(t *testing.T) {
+	reformatted := `{
+		"info": {"version": "1.0", "title": "Pets"}, "swagger": "2.0", "basePath": "/v1",
+		"paths": {"/pets": {"get": {"responses": {"200": {"description": "ok"}}, "operationId": "listPets"}}},
+		"schemes": ["http", "https"], "host": "pets.example.com"}`
+	want := hashCanonicalSpec(swaggerJSON)
+	for name, spec := range map[string]string{"reformatted": reformatted, "yaml": swaggerYAML} {
+		if got := hashCanonicalSpec(spec); got != want {
+			t.Errorf("%s: got %s, want %s", name, got, want)
+		}
+	}
+	if got := hashCanonicalSpec(strings.Replace(swaggerJSON, "listPets", "getPets", 1)); got == want {
+		t.Errorf("a changed spec has the same hash")
+	}
+}
+
+func TestSpecType(t *testing.T) {
+	for _, tt := range []struct {
+		name, spec, want string
+	}{
+		{"swagger json", swaggerJSON, swaggerSpec},
+		{"swagger yaml", swaggerYAML, swaggerSpec},
+		{"openapi 3", oas3YAML, oas3Spec},
+		{"openapi 3.1", `{"openapi": "3.1.0"}`, oas3Spec},
+		{"wsdl", wsdl11, wsdlSpec},
+		{"wsdl with a bom", "\ufeff" + wsdl20, wsdlSpec},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := specType(tt.spec)
+			if err != nil || got != tt.want {
+				t.Errorf("got %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+	for name, spec := range map[string]string{
+		"swagger 1.2": `{"swaggerVersion": "1.2"}`,
+		"openapi 2":   `{"openapi": "2.0"}`,
+		"not a wsdl":  `<html></html>`,
+		"not a spec":  `just a string`,
+	} {
+		if got, err := specType(spec); err == nil {
+			t.Errorf("%s: got %q, want an error", name, got)
+		}
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	for _, tt := range []struct {
+		name, spec string
+		want       specInfo
+	}{
+		{"swagger", swaggerYAML, specInfo{Title: "Pets", Version: "1.0", BasePath: "/v1",
+			Servers: []string{"http://pets.example.com/v1", "https://pets.example.com/v1"}}},
+		{"swagger without schemes", `{"swagger": "2.0", "info": {"title": "t", "version": "1"}, "host": "h:8443", "paths": {}}`,
+			specInfo{Title: "t", Version: "1", Servers: []string{"https://h:8443"}}},
+		{"swagger without host", `{"swagger": "2.0", "info": {"title": "t", "version": "1"}, "basePath": "/b", "paths": {}}`,
+			specInfo{Title: "t", Version: "1", BasePath: "/b", Servers: []string{}}},
+		// the base path is the one of the first server.
+		{"openapi 3", oas3YAML, specInfo{Title: "Pets", Version: "2.0", Description: "All the pets", BasePath: "/api/v2",
+			Servers: []string{"https://pets.example.com/api/v2", "http://localhost:8080/"}}},
+		{"openapi 3 without servers", `{"openapi": "3.0.0", "info": {"title": "t", "version": "1"}, "paths": {}}`,
+			specInfo{Title: "t", Version: "1", Servers: []string{}}},
+		{"openapi 3 relative server", `{"openapi": "3.0.0", "info": {"title": "t", "version": "1"}, "servers": [{"url": "/api"}], "paths": {}}`,
+			specInfo{Title: "t", Version: "1", BasePath: "/api", Servers: []string{"/api"}}},
+		{"wsdl", wsdl11, specInfo{Title: "Pets", Servers: []string{"http://pets.example.com/soap"}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSpec(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSpecErrors(t *testing.T) {
+	for name, spec := range map[string]string{
+		"malformed json":     `{"swagger": "2.0",`,
+		"wrong types":        `{"swagger": "2.0", "info": "pets"}`,
+		"no info":            `{"swagger": "2.0", "paths": {}}`,
+		"no title":           `{"swagger": "2.0", "info": {"version": "1"}, "paths": {}}`,
+		"no version":         `{"openapi": "3.0.0", "info": {"title": "t"}, "paths": {}}`,
+		"no paths":           `{"swagger": "2.0", "info": {"title": "t", "version": "1"}}`,
+		"relative path":      `{"swagger": "2.0", "info": {"title": "t", "version": "1"}, "paths": {"pets": {}}}`,
+		"relative base path": `{"swagger": "2.0", "info": {"title": "t", "version": "1"}, "basePath": "v1", "paths": {}}`,
+		"not a wsdl":         `<html></html>`,
+		"malformed wsdl":     `<definitions><service></definitions>`,
+	} {
+		if got, err := parseSpec(spec); err == nil {
+			t.Errorf("%s: got %+v, want an error", name, got)
+		}
+	}
+}
+
+func TestParseWSDL(t *testing.T) {
+	for _, tt := range []struct {
+		name, spec string
+		want       specInfo
+	}{
+		{"wsdl 1.1", wsdl11, specInfo{Title: "Pets", Servers: []string{"http://pets.example.com/soap"}}},
+		{"wsdl 2.0", wsdl20, specInfo{Servers: []string{}}},
+		// the root is the first element, after the prolog and comments.
+		{"prolog", `<?xml version="1.0"?><!-- pets --><definitions name="P"/>`, specInfo{Title: "P", Servers: []string{}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseWSDL(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+	for name, spec := range map[string]string{
+		"other root":  `<schema><definitions/></schema>`,
+		"empty":       `<?xml version="1.0"?>`,
+		"not closed":  `<definitions>`,
+		"not xml":     `{}`,
+		"nested root": `<soap:Envelope><definitions/></soap:Envelope>`,
+	} {
+		if got, err := parseWSDL(spec); err == nil {
+			t.Errorf("%s: got %+v, want an error", name, got)
+		}
+	}
+}
+
+func TestSpecOperations(t *testing.T) {
+	ops, err := specOperations(oas3YAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 2 || !strings.HasPrefix(ops["GET /pets/{id}"].(string), "getPet#") || !strings.HasPrefix(ops["DELETE /pets/{id}"].(string), "#") {
+		t.Errorf("got %v, want GET and DELETE /pets/{id}", ops)
+	}
+
+	// the fingerprints do not depend on the formatting, but on the content of the operations.
+	json, err := specOperations(swaggerJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	yaml, err := specOperations(swaggerYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(json, yaml) || len(json) != 1 {
+		t.Errorf("got %v and %v, want the same single operation", json, yaml)
+	}
+	changed, err := specOperations(strings.Replace(swaggerJSON, `"ok"`, `"fine"`, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(json, changed) {
+		t.Errorf("a changed operation has the same fingerprint: %v", changed)
+	}
+
+	if ops, err := specOperations(wsdl11); err != nil || len(ops) != 0 {
+		t.Errorf("got %v, %v for a WSDL, want no operation", ops, err)
+	}
+	if ops, err := specOperations(`{"paths": {"/a": {"get": "x"}}}`); err == nil {
+		t.Errorf("got %v, want an error", ops)
+	}
+}
@@ -2,6 +2,7 @@ package axwayapi
 
 import (
 	"context"
+	"net/http"
 	"net/url"
 	"time"
 
@@ -44,14 +45,27 @@ func Provider() *schema.Provider {
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"axwayapi_config":       resourceConfig(),
-			"axwayapi_organization": resourceOrganization(),
-			"axwayapi_user":         resourceUser(),
-			"axwayapi_backend":      resourceBackend(),
-			"axwayapi_frontend":     resourceFrontend(),
-			"axwayapi_application":  resourceApplication(),
+			"axwayapi_config":                      resourceConfig(),
+			"axwayapi_config_password_policy":      resourceConfigPasswordPolicy(),
+			"axwayapi_config_global_policies":      resourceConfigGlobalPolicies(),
+			"axwayapi_organization":                resourceOrganization(),
+			"axwayapi_user":                        resourceUser(),
+			"axwayapi_backend":                     resourceBackend(),
+			"axwayapi_frontend":                    resourceFrontend(),
+			"axwayapi_application":                 resourceApplication(),
+			"axwayapi_application_external_client": resourceApplicationExternalClient(),
+			"axwayapi_application_permission":      resourceApplicationPermission(),
+			"axwayapi_system_quota_restriction":    resourceSystemQuotaRestriction(),
+			"axwayapi_api_import":                  resourceApiImport(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"axwayapi_quota":                 dataSourceQuota(),
+			"axwayapi_policies":              dataSourcePolicies(),
+			"axwayapi_token_store":           dataSourceTokenStore(),
+			"axwayapi_authentication_policy": dataSourceAuthenticationPolicy(),
+			"axwayapi_backend_methods":       dataSourceBackendMethods(),
+			"axwayapi_api_export":            dataSourceApiExport(),
 		},
-		DataSourcesMap:       map[string]*schema.Resource{},
 		ConfigureContextFunc: providerConfigure,
 	}
 }
@@ -65,6 +79,23 @@ type ProviderState struct {
 	skipTlsCertVerif bool
 }
 
+// The downloads done by the provider itself, e.g. of a spec or an image, are given up after this.
+const downloadTimeout = time.Minute
+
+// downloadClient gives the client of the downloads done by the provider itself.
+// They go through the proxy of the provider, if any. The certificates are always verified,
+// 'skip_tls_cert_verif' being about the API Manager only.
+func (prov *ProviderState) downloadClient() *http.Client {
+	proxy := http.ProxyFromEnvironment
+	if prov.proxy != nil {
+		proxy = http.ProxyURL(prov.proxy)
+	}
+	return &http.Client{
+		Timeout:   downloadTimeout,
+		Transport: &http.Transport{Proxy: proxy},
+	}
+}
+
 func (prov *ProviderState) GetClient() (*axwayapi.Client, error) {
 	if prov.Client != nil {
 		return prov.Client, nil
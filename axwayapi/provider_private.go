@@ -0,0 +1,125 @@
+package axwayapi
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+)
+
+// The SDK gives no way to keep data in the private state of a resource: what is kept
+// there by the resources of this provider is carried by this server, around the one
+// of the SDK, and handed to the CRUD functions through their context.
+// The private state is stored along the state, but never shown, nor compared on plan.
+
+// privateKey is the key of the data of this provider in the private state,
+// next to the ones of the SDK.
+const privateKey = "axwayapi"
+
+type privateData map[string]string
+
+type privateDataKey struct{}
+
+// ProviderServer serves the provider, keeping the private data of its resources.
+func ProviderServer() tfprotov5.ProviderServer {
+	return &privateStateServer{Provider().GRPCProvider()}
+}
+
+type privateStateServer struct {
+	tfprotov5.ProviderServer
+}
+
+// getPrivate gives the private data of the resource being applied.
+func getPrivate(ctx context.Context, key string) string {
+	if p, ok := ctx.Value(privateDataKey{}).(privateData); ok {
+		return p[key]
+	}
+	return ""
+}
+
+// setPrivate keeps a private data of the resource being applied. An empty value removes it.
+func setPrivate(ctx context.Context, key, value string) {
+	if p, ok := ctx.Value(privateDataKey{}).(privateData); ok {
+		if value == "" {
+			delete(p, key)
+		} else {
+			p[key] = value
+		}
+	}
+}
+
+func readPrivate(b []byte) (map[string]interface{}, privateData, error) {
+	all := map[string]interface{}{}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &all); err != nil {
+			return nil, nil, err
+		}
+	}
+	p := privateData{}
+	if m, ok := all[privateKey].(map[string]interface{}); ok {
+		for k, v := range m {
+			if s, ok := v.(string); ok {
+				p[k] = s
+			}
+		}
+	}
+	return all, p, nil
+}
+
+func writePrivate(b []byte, p privateData) ([]byte, error) {
+	all, _, err := readPrivate(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(p) == 0 {
+		if _, ok := all[privateKey]; !ok {
+			return b, nil
+		}
+		delete(all, privateKey)
+	} else {
+		all[privateKey] = p
+	}
+	return json.Marshal(all)
+}
+
+// The SDK plans a private state of its own when the resource changes: ours is carried over.
+func (s *privateStateServer) PlanResourceChange(ctx context.Context, req *tfprotov5.PlanResourceChangeRequest) (*tfprotov5.PlanResourceChangeResponse, error) {
+	resp, err := s.ProviderServer.PlanResourceChange(ctx, req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	_, p, err := readPrivate(req.PriorPrivate)
+	if err == nil {
+		resp.PlannedPrivate, err = writePrivate(resp.PlannedPrivate, p)
+	}
+	if err != nil {
+		resp.Diagnostics = append(resp.Diagnostics, privateDiagnostic(err))
+	}
+	return resp, nil
+}
+
+func (s *privateStateServer) ApplyResourceChange(ctx context.Context, req *tfprotov5.ApplyResourceChangeRequest) (*tfprotov5.ApplyResourceChangeResponse, error) {
+	_, p, err := readPrivate(req.PlannedPrivate)
+	if err != nil {
+		return &tfprotov5.ApplyResourceChangeResponse{
+			NewState:    req.PriorState,
+			Diagnostics: []*tfprotov5.Diagnostic{privateDiagnostic(err)},
+		}, nil
+	}
+	resp, err := s.ProviderServer.ApplyResourceChange(context.WithValue(ctx, privateDataKey{}, p), req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	if resp.Private, err = writePrivate(resp.Private, p); err != nil {
+		resp.Diagnostics = append(resp.Diagnostics, privateDiagnostic(err))
+	}
+	return resp, nil
+}
+
+func privateDiagnostic(err error) *tfprotov5.Diagnostic {
+	return &tfprotov5.Diagnostic{
+		Severity: tfprotov5.DiagnosticSeverityError,
+		Summary:  "invalid private state",
+		Detail:   err.Error(),
+	}
+}
@@ -0,0 +1,204 @@
+package axwayapi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// The import of a collection of apis, as exported by axwayapi_api_export.
+// The frontends it creates are the ones given back by the import, and the backends the new ones
+// they use: they are deleted with this resource. When the import does not tell its frontends,
+// nothing is tracked, as other frontends may be created meanwhile.
+var TFApiImportSchema = schemaMap{
+	"org_id": desc(_FORCENEW(required(_string())), "The organization the apis are imported into"),
+	"content_base64": desc(_onCreateOnly(_FORCENEW(required(_hashedString()))),
+		`The collection (a .dat file), base64 encoded, e.g. the 'content_base64' of an axwayapi_api_export.
+		Its changes are ignored once imported: two exports of the same apis differ, being encrypted.
+		Change 'triggers' to import the apis again.`),
+	"triggers": desc(_FORCENEW(optional(_map(schema.TypeString))),
+		"Any values, the apis being imported again when they change, e.g. the version of the exported apis"),
+	"passphrase": desc(_FORCENEW(_sensitive(required(_string()))),
+		"The passphrase the collection was encrypted with"),
+	"frontend_ids": desc(readonly(_plist(schema.TypeString)), "The ids of the imported frontends, none when the import does not tell them"),
+	"backend_ids":  desc(readonly(_plist(schema.TypeString)), "The ids of the imported backends"),
+}
+
+func resourceApiImport() *schema.Resource {
+	return &schema.Resource{
+		Schema:        TFApiImportSchema,
+		CreateContext: resourceApiImportCreate,
+		ReadContext:   resourceApiImportRead,
+		DeleteContext: resourceApiImportDelete,
+	}
+}
+
+func resourceApiImportCreate(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	content, err := base64.StdEncoding.DecodeString(d.Get("content_base64").(string))
+	if err != nil {
+		return diag.Errorf("the collection is not base64 encoded: %v", err)
+	}
+
+	orgId := d.Get("org_id").(string)
+
+	_, backends, err := apiIds(c)
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+	fields := map[string]string{
+		"organizationId": orgId,
+		"passphrase":     d.Get("passphrase").(string),
+	}
+	b, err := restPostParts(c, "proxies/import", fields, restFile{"file", "api-export.dat", "application/octet-stream", content})
+	if err != nil {
+		diags = append(diags, diag.Errorf("import of the apis failed: %v", err)...)
+		return diags
+	}
+	imported := []client.Frontend{}
+	if err = json.Unmarshal(b, &imported); err != nil || imported == nil {
+		imported = nil
+		diags = warn(diags, "the import did not tell which apis it created: they are not deleted with this resource")
+	}
+
+	frontendIds := []string{}
+	backendIds := []string{}
+	seen := map[string]bool{}
+	for i := range imported {
+		frontend := &imported[i]
+		frontendIds = append(frontendIds, frontend.Id)
+		if f, err := c.GetFrontend(frontend.Id); err == nil {
+			frontend = f
+		}
+		// the backends which existed already are left alone.
+		for _, id := range backendsOf(frontend) {
+			if !backends[id] && !seen[id] {
+				seen[id] = true
+				backendIds = append(backendIds, id)
+			}
+		}
+	}
+	sort.Strings(frontendIds)
+	sort.Strings(backendIds)
+	if imported != nil && len(frontendIds) == 0 {
+		diags = warn(diags, "the import created no api, they might exist already")
+	}
+	d.SetId(fmt.Sprintf("%s/%.16s", orgId, _hash(d.Get("content_base64"))))
+	d.Set("frontend_ids", frontendIds)
+	d.Set("backend_ids", backendIds)
+
+	return diags
+}
+
+// backendsOf gives the ids of the backends the frontend uses.
+func backendsOf(frontend *client.Frontend) []string {
+	r := []string{}
+	if frontend.ApiId != "" {
+		r = append(r, frontend.ApiId)
+	}
+	for _, p := range frontend.OutboundProfiles {
+		if p.ApiId != "" {
+			r = append(r, p.ApiId)
+		}
+	}
+	for _, p := range frontend.ServiceProfiles {
+		if p.ApiId != "" {
+			r = append(r, p.ApiId)
+		}
+	}
+	return r
+}
+
+func resourceApiImportRead(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	frontends, backends, err := apiIds(c)
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+	// the apis deleted since are forgotten, but not the import: it is done once.
+	frontendIds := existingIds(d.Get("frontend_ids"), frontends)
+	backendIds := existingIds(d.Get("backend_ids"), backends)
+	d.Set("frontend_ids", frontendIds)
+	d.Set("backend_ids", backendIds)
+
+	return diags
+}
+
+func resourceApiImportDelete(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	for _, id := range toStringArray(d.Get("frontend_ids")) {
+		frontend, err := c.GetFrontend(id)
+		if err != nil {
+			diags = warn(diags, "the api %s cannot be read, it is not deleted: %v", id, err)
+			continue
+		}
+		diags = append(diags, adaptStates(c, unpublished, time.Time{}, frontend)...)
+		if diags.HasError() {
+			return diags
+		}
+		if err = c.DeleteFrontend(id); err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+			return diags
+		}
+	}
+	for _, id := range toStringArray(d.Get("backend_ids")) {
+		if err = c.DeleteBackend(id); err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+			return diags
+		}
+	}
+
+	return diags
+}
+
+// apiIds gives the ids of the existing frontends and backends.
+func apiIds(c *client.Client) (frontends, backends map[string]bool, err error) {
+	fl, err := listFrontends(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the client does not list the backends.
+	bl := []client.Backend{}
+	if err = restGet(c, &bl, "apirepo"); err != nil {
+		return nil, nil, err
+	}
+	frontends = make(map[string]bool, len(fl))
+	for _, f := range fl {
+		frontends[f.Id] = true
+	}
+	backends = make(map[string]bool, len(bl))
+	for _, b := range bl {
+		backends[b.Id] = true
+	}
+	return frontends, backends, nil
+}
+
+func existingIds(ids interface{}, existing map[string]bool) []string {
+	r := []string{}
+	for _, id := range toStringArray(ids) {
+		if existing[id] {
+			r = append(r, id)
+		}
+	}
+	return r
+}
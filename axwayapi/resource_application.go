@@ -2,17 +2,18 @@ package axwayapi
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
 	client "github.com/axway-techlab/axwayapi_client/axwayapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-// TODO: Deal with tags.
-// They are ignored for now, since they are encoded as
-// anonymous fields in the application directly.
-// Decoding them is hacky, and error-prone.
 var TFApplicationSchema = &schemaMap{
 	"id":          readonly(_string()),
 	"name":        required(_string()),
@@ -21,9 +22,21 @@ var TFApplicationSchema = &schemaMap{
 	"phone":       optional(_string()),
 	"email":       optional(_string()),
 	"enabled":     optional(_bool(), true),
-	"image_jpg":   optional(_hashedString()),
+	"image_jpg":   _imageJpg(optional(_hashedString())),
+	"image":       _image(),
+	"image_hash":  _imageHash(),
+	"image_saved": _imageSaved(),
 	"state":       readonly(_string()),
-	//	"tags":        inOut(_map(schema.TypeString)),
+	"approval": desc(inOut(_string(oneOf(approved, rejected))),
+		`Can be 'approved' or 'rejected'. When set, the application is approved or rejected accordingly.
+		Needed only when 'auto_approve_applications' is off in the configuration.
+		An application cannot go back to pending: while it is, 'state' tells it.`),
+	"wait_for_approval": desc(optional(_bool(), false),
+		`When true, and 'approval' does not decide, wait for the application to be approved or rejected
+		by someone else (e.g. in the UI). The wait is bounded by the create/update timeouts.`),
+	"tag": optional(_setMin(1, TFTag)),
+	"custom_properties": desc(inOut(_map(schema.TypeString)),
+		"The values of the custom properties defined for applications in the API Manager configuration"),
 	"managed_by": readonly(_plist(schema.TypeString)),
 	"created_by": readonly(_string()),
 	"created_on": readonly(_int()),
@@ -45,6 +58,94 @@ var TFApiKey = &schema.Resource{
 	},
 }
 
+// The client library ignores the tags and the custom properties of applications.
+// The latter are encoded by the server as extra top-level fields of the
+// application, so any field that is not a known one is a custom property.
+type ApplicationEx struct {
+	client.Application
+	Tags             map[string][]string
+	CustomProperties map[string]interface{}
+}
+
+// fields of an application that are neither in client.Application nor custom properties.
+var applicationExtraFields = []string{"tags", "image", "apis"}
+
+func (a ApplicationEx) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(a.Application)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	if err = json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	for k, v := range a.CustomProperties {
+		m[k] = v
+	}
+	if a.Tags != nil {
+		m["tags"] = a.Tags
+	}
+	return json.Marshal(m)
+}
+
+func (a *ApplicationEx) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &a.Application); err != nil {
+		return err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	a.Tags = map[string][]string{}
+	if t, ok := raw["tags"]; ok && string(t) != "null" {
+		if err := json.Unmarshal(t, &a.Tags); err != nil {
+			return err
+		}
+	}
+	known := map[string]bool{}
+	for _, f := range applicationExtraFields {
+		known[f] = true
+	}
+	ty := reflect.TypeOf(a.Application)
+	for i := 0; i < ty.NumField(); i++ {
+		known[strings.Split(ty.Field(i).Tag.Get("json"), ",")[0]] = true
+	}
+	a.CustomProperties = map[string]interface{}{}
+	for k, v := range raw {
+		if known[k] {
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			// Not a string, hence not a custom property.
+			continue
+		}
+		a.CustomProperties[k] = s
+	}
+	return nil
+}
+
+func createApplication(c *client.Client, application *ApplicationEx) error {
+	// At creation time, this field must be set to the empty array...
+	application.Apis = &[]string{}
+	return restPost(c, application, "applications")
+}
+
+func getApplication(c *client.Client, id string) (ret *ApplicationEx, err error) {
+	ret = &ApplicationEx{}
+	err = restGet(c, ret, fmt.Sprintf("applications/%s", id))
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func updateApplication(c *client.Client, application *ApplicationEx) error {
+	// At update time, this field must be omitted...
+	application.Apis = nil
+	return restPut(c, application, fmt.Sprintf("applications/%s", application.Id))
+}
+
 func resourceApplication() *schema.Resource {
 	return &schema.Resource{
 		Schema:        *TFApplicationSchema,
@@ -52,6 +153,11 @@ func resourceApplication() *schema.Resource {
 		ReadContext:   resourceApplicationRead,
 		UpdateContext: resourceApplicationUpdate,
 		DeleteContext: resourceApplicationDelete,
+		CustomizeDiff: customdiff.All(customizeRestrictions("quota"), customizeImage),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+		},
 	}
 }
 
@@ -61,19 +167,20 @@ func resourceApplicationCreate(ctx context.Context, d *schema.ResourceData, m in
 		return diag.FromErr(err)
 	}
 
-	application := &client.Application{}
+	application := &ApplicationEx{}
 	expandApplication(d, application)
 
-	err = c.CreateApplication(application)
+	err = createApplication(c, application)
 	if err != nil {
 		diags = append(diags, diag.FromErr(err)...)
 		return diags
 	}
 
-	diags = append(diags, syncImage(d, application, c)...)
-	diags = append(diags, syncApplicationApis(d, application, c)...)
-	diags = append(diags, syncApplicationApiKeys(d, application, c)...)
-	diags = append(diags, syncQuota(d, application, c)...)
+	diags = append(diags, syncImage(ctx, m, d, &application.Application, c)...)
+	diags = append(diags, syncApplicationApis(d, &application.Application, c)...)
+	diags = append(diags, syncApplicationApiKeys(d, &application.Application, c)...)
+	diags = append(diags, syncQuota(d, &application.Application, c)...)
+	diags = append(diags, syncApproval(ctx, d, application, c, d.Timeout(schema.TimeoutCreate))...)
 
 	flattenApplication(application, d)
 
@@ -86,12 +193,13 @@ func resourceApplicationRead(ctx context.Context, d *schema.ResourceData, m inte
 		return diag.FromErr(err)
 	}
 
-	application, err := c.GetApplication(d.Id())
+	application, err := getApplication(c, d.Id())
 	if err != nil {
 		diags = append(diags, diag.FromErr(err)...)
 		return diags
 	}
 	flattenApplication(application, d)
+	readImage(c, d, &application.Application)
 
 	return diags
 }
@@ -102,19 +210,20 @@ func resourceApplicationUpdate(ctx context.Context, d *schema.ResourceData, m in
 		return diag.FromErr(err)
 	}
 
-	application := &client.Application{}
+	application := &ApplicationEx{}
 	expandApplication(d, application)
 
-	err = c.UpdateApplication(application)
+	err = updateApplication(c, application)
 	if err != nil {
 		diags = append(diags, diag.FromErr(err)...)
 		return diags
 	}
 
-	diags = append(diags, syncImage(d, application, c)...)
-	diags = append(diags, syncApplicationApis(d, application, c)...)
-	diags = append(diags, syncApplicationApiKeys(d, application, c)...)
-	diags = append(diags, syncQuota(d, application, c)...)
+	diags = append(diags, syncImage(ctx, m, d, &application.Application, c)...)
+	diags = append(diags, syncApplicationApis(d, &application.Application, c)...)
+	diags = append(diags, syncApplicationApiKeys(d, &application.Application, c)...)
+	diags = append(diags, syncQuota(d, &application.Application, c)...)
+	diags = append(diags, syncApproval(ctx, d, application, c, d.Timeout(schema.TimeoutUpdate))...)
 
 	flattenApplication(application, d)
 
@@ -136,6 +245,62 @@ func resourceApplicationDelete(ctx context.Context, d *schema.ResourceData, m in
 	return diags
 }
 
+const (
+	pending  = "pending"
+	approved = "approved"
+	rejected = "rejected"
+)
+
+// Approves or rejects the application as requested, or waits for someone
+// else to do so. The application object is refreshed with its latest state.
+func syncApproval(ctx context.Context, d *schema.ResourceData, application *ApplicationEx, c *client.Client, timeout time.Duration) (diags diag.Diagnostics) {
+	fresh, err := getApplication(c, application.Id)
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+	*application = *fresh
+
+	wanted, ok := d.GetOk("approval")
+	if ok && wanted.(string) != application.State {
+		switch transition := application.State + " -> " + wanted.(string); transition {
+		case pending + " -> " + approved, rejected + " -> " + approved:
+			err = restPost(c, &application.Application, fmt.Sprintf("applications/%s/approve", application.Id))
+		case pending + " -> " + rejected, approved + " -> " + rejected:
+			err = restPost(c, &application.Application, fmt.Sprintf("applications/%s/reject", application.Id))
+		default:
+			diags = warn(diags, "approval transition (%s) is not possible: ignoring this change", transition)
+		}
+		if err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+			return diags
+		}
+	}
+
+	if d.Get("wait_for_approval").(bool) {
+		deadline := time.Now().Add(timeout)
+		for application.State == pending {
+			if time.Now().After(deadline) {
+				diags = append(diags, diag.Errorf("timeout reached: application %s is still pending approval", application.Id)...)
+				return diags
+			}
+			select {
+			case <-ctx.Done():
+				diags = append(diags, diag.Errorf("stopped waiting for the approval of application %s: %v", application.Id, ctx.Err())...)
+				return diags
+			case <-time.After(10 * time.Second):
+			}
+			fresh, err := getApplication(c, application.Id)
+			if err != nil {
+				diags = append(diags, diag.FromErr(err)...)
+				return diags
+			}
+			*application = *fresh
+		}
+	}
+	return diags
+}
+
 func syncQuota(d *schema.ResourceData, application *client.Application, c *client.Client) (diags diag.Diagnostics) {
 	quota := &client.Quota{}
 	err := c.GetQuotaForApplication(application.Id, quota)
@@ -148,6 +313,10 @@ func syncQuota(d *schema.ResourceData, application *client.Application, c *clien
 	if wanted, ok := d.GetOk("quota"); ok {
 		quota := &client.Quota{}
 		expandQuota2(wanted, "quota for "+application.Name, quota)
+		if err := resolveRestrictionMethods(c, quota.Restrictions); err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+			return diags
+		}
 		if hasQuota {
 			c.UpdateQuotaForApplication(application, quota)
 		} else {
@@ -240,7 +409,7 @@ func syncApplicationApis(d *schema.ResourceData, application *client.Application
 	return diags
 }
 
-func flattenApplication(c *client.Application, d *schema.ResourceData) {
+func flattenApplication(c *ApplicationEx, d *schema.ResourceData) {
 	d.SetId(c.Id)
 	d.Set("name", c.Name)
 	d.Set("description", c.Description)
@@ -249,7 +418,13 @@ func flattenApplication(c *client.Application, d *schema.ResourceData) {
 	d.Set("email", c.Email)
 	d.Set("enabled", c.Enabled)
 	d.Set("state", c.State)
-	//	d.Set("tags", c.Tags)
+	// pending is only a state: once decided, an application is never pending again.
+	switch c.State {
+	case approved, rejected:
+		d.Set("approval", c.State)
+	}
+	d.Set("tag", flattenTags(c.Tags))
+	d.Set("custom_properties", c.CustomProperties)
 	d.Set("created_by", c.CreatedBy)
 	d.Set("managed_by", c.ManagedBy)
 	d.Set("created_on", c.CreatedOn)
@@ -257,7 +432,7 @@ func flattenApplication(c *client.Application, d *schema.ResourceData) {
 }
 
 // ####### //
-func expandApplication(d *schema.ResourceData, application *client.Application) {
+func expandApplication(d *schema.ResourceData, application *ApplicationEx) {
 	application.Id = d.Id()
 	application.Name = d.Get("name").(string)
 	application.Description = d.Get("description").(string)
@@ -266,9 +441,11 @@ func expandApplication(d *schema.ResourceData, application *client.Application)
 	application.Email = d.Get("email").(string)
 	application.Enabled = d.Get("enabled").(bool)
 	application.State = d.Get("state").(string)
-	//	if v, ok := d.GetOk("tags"); ok {
-	//		application.Tags = toStringMap(v.(map[string]interface{}))
-	//	}
+	application.Tags = map[string][]string{}
+	if v, ok := d.GetOk("tag"); ok {
+		application.Tags = toTags(v.(*schema.Set))
+	}
+	application.CustomProperties = d.Get("custom_properties").(map[string]interface{})
 	application.ManagedBy = toStringArray(d.Get("managed_by"))
 }
 
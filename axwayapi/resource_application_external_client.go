@@ -0,0 +1,161 @@
+package axwayapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// External credentials map the client id issued by an external IdP
+// onto an application, where API keys would otherwise be used.
+// The id of this resource is "<application_id>/<external client id>",
+// which is also the form expected by 'terraform import'.
+var TFExtClientSchema = schemaMap{
+	"application_id": _FORCENEW(required(_string())),
+	"client_id":      desc(required(_string()), "The client id, as known by the external identity provider"),
+	"enabled":        desc(optional(_bool(), true), "defaults to 'true'"),
+	"cors_origins":   optional(_plist(schema.TypeString)),
+	"created_by":     readonly(_string()),
+	"created_on":     readonly(_int()),
+}
+
+type ExtClient struct {
+	Id            string   `json:"id,omitempty"`
+	ApplicationId string   `json:"applicationId"`
+	ClientId      string   `json:"clientId"`
+	Enabled       bool     `json:"enabled"`
+	CorsOrigins   []string `json:"corsOrigins"`
+	CreatedBy     string   `json:"createdBy,omitempty"`
+	CreatedOn     int      `json:"createdOn,omitempty"`
+}
+
+func resourceApplicationExternalClient() *schema.Resource {
+	return &schema.Resource{
+		Schema:        TFExtClientSchema,
+		CreateContext: resourceExtClientCreate,
+		ReadContext:   resourceExtClientRead,
+		UpdateContext: resourceExtClientUpdate,
+		DeleteContext: resourceExtClientDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceExtClientCreate(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	extClient := &ExtClient{}
+	expandExtClient(d, extClient)
+
+	err = restPost(c, extClient, fmt.Sprintf("applications/%s/extclients", extClient.ApplicationId), http.StatusCreated)
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+
+	flattenExtClient(extClient, d)
+
+	return diags
+}
+
+func resourceExtClientRead(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ids, err := splitId(d.Id(), "application_id", "external_client_id")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	appId, id := ids[0], ids[1]
+	extClients, err := listExtClients(c, appId)
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+	for _, extClient := range extClients {
+		if extClient.Id == id {
+			flattenExtClient(&extClient, d)
+			return diags
+		}
+	}
+	// Removed outside of terraform: forget it, so that it gets re-created.
+	d.SetId("")
+	return diags
+}
+
+func resourceExtClientUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	extClient := &ExtClient{}
+	expandExtClient(d, extClient)
+
+	err = restPut(c, extClient, fmt.Sprintf("applications/%s/extclients/%s", extClient.ApplicationId, extClient.Id))
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+
+	flattenExtClient(extClient, d)
+
+	return diags
+}
+
+func resourceExtClientDelete(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ids, err := splitId(d.Id(), "application_id", "external_client_id")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = restDelete(c, fmt.Sprintf("applications/%s/extclients/%s", ids[0], ids[1]), http.StatusNoContent)
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+
+	return diags
+}
+
+func listExtClients(c *client.Client, appId string) (ret []ExtClient, err error) {
+	err = restGet(c, &ret, fmt.Sprintf("applications/%s/extclients", appId))
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func flattenExtClient(extClient *ExtClient, d *schema.ResourceData) {
+	d.SetId(extClient.ApplicationId + "/" + extClient.Id)
+	d.Set("application_id", extClient.ApplicationId)
+	d.Set("client_id", extClient.ClientId)
+	d.Set("enabled", extClient.Enabled)
+	d.Set("cors_origins", extClient.CorsOrigins)
+	d.Set("created_by", extClient.CreatedBy)
+	d.Set("created_on", extClient.CreatedOn)
+}
+
+func expandExtClient(d *schema.ResourceData, extClient *ExtClient) {
+	if ids, err := splitId(d.Id(), "application_id", "external_client_id"); err == nil {
+		extClient.Id = ids[1]
+	}
+	extClient.ApplicationId = d.Get("application_id").(string)
+	extClient.ClientId = d.Get("client_id").(string)
+	extClient.Enabled = d.Get("enabled").(bool)
+	extClient.CorsOrigins = toStringArray(d.Get("cors_origins"))
+}
@@ -0,0 +1,153 @@
+package axwayapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Shares an application with another user, so that they can see or manage it.
+// The id of this resource is "<application_id>/<permission id>",
+// which is also the form expected by 'terraform import'.
+var TFApplicationPermissionSchema = schemaMap{
+	"application_id": _FORCENEW(required(_string())),
+	"user_id":        _FORCENEW(required(_string())),
+	"permission":     desc(required(_string(oneOf("view", "manage"))), "Can be 'view' or 'manage'"),
+	"created_by":     readonly(_string()),
+}
+
+type ApplicationPermission struct {
+	Id         string `json:"id,omitempty"`
+	UserId     string `json:"userId"`
+	Permission string `json:"permission"`
+	CreatedBy  string `json:"createdBy,omitempty"`
+}
+
+func resourceApplicationPermission() *schema.Resource {
+	return &schema.Resource{
+		Schema:        TFApplicationPermissionSchema,
+		CreateContext: resourceApplicationPermissionCreate,
+		ReadContext:   resourceApplicationPermissionRead,
+		UpdateContext: resourceApplicationPermissionUpdate,
+		DeleteContext: resourceApplicationPermissionDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceApplicationPermissionCreate(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	appId := d.Get("application_id").(string)
+	permission := &ApplicationPermission{}
+	expandApplicationPermission(d, permission)
+
+	err = restPost(c, permission, fmt.Sprintf("applications/%s/permissions", appId), http.StatusCreated)
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+
+	flattenApplicationPermission(appId, permission, d)
+
+	return diags
+}
+
+func resourceApplicationPermissionRead(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ids, err := splitId(d.Id(), "application_id", "permission_id")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	appId, id := ids[0], ids[1]
+	permissions, err := listApplicationPermissions(c, appId)
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+	for _, permission := range permissions {
+		if permission.Id == id {
+			flattenApplicationPermission(appId, &permission, d)
+			return diags
+		}
+	}
+	// Removed outside of terraform: forget it, so that it gets re-created.
+	d.SetId("")
+	return diags
+}
+
+func resourceApplicationPermissionUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	appId := d.Get("application_id").(string)
+	permission := &ApplicationPermission{}
+	expandApplicationPermission(d, permission)
+
+	err = restPut(c, permission, fmt.Sprintf("applications/%s/permissions/%s", appId, permission.Id))
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+
+	flattenApplicationPermission(appId, permission, d)
+
+	return diags
+}
+
+func resourceApplicationPermissionDelete(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ids, err := splitId(d.Id(), "application_id", "permission_id")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = restDelete(c, fmt.Sprintf("applications/%s/permissions/%s", ids[0], ids[1]), http.StatusNoContent)
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+
+	return diags
+}
+
+func listApplicationPermissions(c *client.Client, appId string) (ret []ApplicationPermission, err error) {
+	err = restGet(c, &ret, fmt.Sprintf("applications/%s/permissions", appId))
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func flattenApplicationPermission(appId string, permission *ApplicationPermission, d *schema.ResourceData) {
+	d.SetId(appId + "/" + permission.Id)
+	d.Set("application_id", appId)
+	d.Set("user_id", permission.UserId)
+	d.Set("permission", permission.Permission)
+	d.Set("created_by", permission.CreatedBy)
+}
+
+func expandApplicationPermission(d *schema.ResourceData, permission *ApplicationPermission) {
+	if ids, err := splitId(d.Id(), "application_id", "permission_id"); err == nil {
+		permission.Id = ids[1]
+	}
+	permission.UserId = d.Get("user_id").(string)
+	permission.Permission = d.Get("permission").(string)
+}
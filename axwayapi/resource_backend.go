@@ -2,6 +2,10 @@ package axwayapi
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
 	"time"
 
 	client "github.com/axway-techlab/axwayapi_client/axwayapi"
@@ -10,13 +14,26 @@ import (
 )
 
 var TFBackendSchema = schemaMap{
-	"swagger":                 _FORCENEW(required(_hashedString())),
+	"id": desc(readonly(_string()), "Changes when the spec is imported again"),
+	"swagger": desc(exactlyOneOfResource(_specString(), "swagger", "url"),
+		`The content of the spec: Swagger 2 or OpenAPI 3, in JSON or YAML, or a WSDL.
+		Changing the spec imports it as a new backend, which the frontends of the old one are moved to
+		(being temporarily unpublished if need be), before the old one is deleted.
+		The plan fails when a frontend referring to the 'id' of the backend forbids it with 'allow_unpublish',
+		and the replacement fails when a frontend using the backend is retired, as it would be published again.
+		A spec changed on the server, e.g. edited in the UI, is handled according to 'on_drift'.`),
+	"url": desc(exactlyOneOfResource(_string(), "swagger", "url"),
+		"The url the API Manager imports the spec from, instead of a 'swagger' content"),
+	"type": desc(inOut(_string(oneOf(specTypes...))),
+		"The type of the spec, detected from its content if none is given"),
+	"imports": desc(optional(_map(schema.TypeString)),
+		"The files imported by a WSDL (e.g. XSDs), by their location relative to it"),
 	"org_id":                  _FORCENEW(required(_string())),
 	"name":                    required(_string()),
-	"base_path":               desc(inOut(_string()), "If none is given, will be read from the Swagger"),
-	"summary":                 desc(inOut(_string()), "If none is given, will be read from the Swagger"),
-	"description":             desc(inOut(_string()), "If none is given, will be read from the Swagger"),
-	"resource_path":           desc(inOut(_string()), "If none is given, will be read from the Swagger"),
+	"base_path":               desc(inOut(_string()), "If none is given, will be read from the spec, see 'spec_info'"),
+	"summary":                 desc(inOut(_string()), "If none is given, will be read from the spec, see 'spec_info'"),
+	"description":             desc(inOut(_string()), "If none is given, will be read from the spec, see 'spec_info'"),
+	"resource_path":           desc(inOut(_string()), "If none is given, will be read from the spec, see 'spec_info'"),
 	"version":                 readonly(_string()),
 	"consumes":                readonly(_plist(schema.TypeString)),
 	"produces":                readonly(_plist(schema.TypeString)),
@@ -28,6 +45,19 @@ var TFBackendSchema = schemaMap{
 	"import_url":              readonly(_string()),
 	"properties":              readonly(_map(schema.TypeString)),
 	"models":                  readonly(_string()),
+	"spec_info": desc(readonly(_list(TFSpecInfo)),
+		"What the spec tells about the api, known from the plan"),
+	"spec_hash": desc(readonly(_string()),
+		"The hash of the spec once reformatted, compared with the one kept by the server"),
+	"on_drift": desc(optional(_string(oneOf(reimportOnDrift, reportOnDrift)), reimportOnDrift),
+		`What to do when the spec is changed on the server, e.g. edited in the UI. Either way, the refresh warns about it.
+		'reimport' makes the whole spec be imported again as a new backend, as when it is changed (see 'swagger'):
+		the plan shows the operations and 'spec_info' of the spec found on the server being changed back,
+		and its published frontends are temporarily unpublished, unless they forbid it with 'allow_unpublish'.
+		'report' only warns, leaving the backend as changed on the server.`),
+	"operations": desc(readonly(_map(schema.TypeString)),
+		`The operations of the spec, by "<VERB> <path>", with their operationId and a fingerprint.
+		The plan of a change of the spec shows which operations are added, removed or changed.`),
 }
 
 func resourceBackend() *schema.Resource {
@@ -37,23 +67,106 @@ func resourceBackend() *schema.Resource {
 		ReadContext:   resourceBackendRead,
 		UpdateContext: resourceBackendUpdate,
 		DeleteContext: resourceBackendDelete,
+		CustomizeDiff: customizeBackend,
 	}
 }
 
+func customizeBackend(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	config := d.GetRawConfig()
+	spec := config.GetAttr("swagger")
+	known := spec.IsKnown() && !spec.IsNull()
+	changed := backendSpecChanged(d)
+	if !changed {
+		// the backends imported before the spec was hashed get their spec_hash.
+		if known && d.Get("spec_hash") == "" {
+			if err := d.SetNew("spec_hash", hashCanonicalSpec(spec.AsString())); err != nil {
+				return err
+			}
+		}
+		// the backends imported before the spec was parsed get their spec_info and operations.
+		// Their spec is checked as a changed one would be.
+		if known && len(d.Get("spec_info").([]interface{})) == 0 {
+			info, err := parseSpec(spec.AsString())
+			if err != nil {
+				return err
+			}
+			ops, err := specOperations(spec.AsString())
+			if err != nil {
+				return err
+			}
+			if err := d.SetNew("operations", ops); err != nil {
+				return err
+			}
+			return d.SetNew("spec_info", info.flatten())
+		}
+		return nil
+	}
+	if !known {
+		if err := d.SetNewComputed("spec_info"); err != nil {
+			return err
+		}
+	} else if info, err := parseSpec(spec.AsString()); err != nil {
+		return err
+	} else if err := d.SetNew("spec_info", info.flatten()); err != nil {
+		return err
+	}
+	if config.GetAttr("type").IsNull() {
+		// the type of the previous spec does not tell the one of the new spec.
+		if !known {
+			if err := d.SetNewComputed("type"); err != nil {
+				return err
+			}
+		} else if ty, err := specType(spec.AsString()); err != nil {
+			return err
+		} else if err := d.SetNew("type", ty); err != nil {
+			return err
+		}
+	}
+	if !known {
+		if err := d.SetNewComputed("spec_hash"); err != nil {
+			return err
+		}
+	} else if err := d.SetNew("spec_hash", hashCanonicalSpec(spec.AsString())); err != nil {
+		return err
+	}
+	if !known {
+		if err := d.SetNewComputed("operations"); err != nil {
+			return err
+		}
+	} else if ops, err := specOperations(spec.AsString()); err != nil {
+		return err
+	} else if err := d.SetNew("operations", ops); err != nil {
+		return err
+	}
+	if d.Id() == "" {
+		return nil
+	}
+	// a new backend is imported
+	for _, k := range []string{"id", "version", "consumes", "produces", "created_on", "created_by", "models"} {
+		if err := d.SetNewComputed(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backendSpecChanged tells whether the backend is to be imported again.
+func backendSpecChanged(d interface{ HasChanges(...string) bool }) bool {
+	return d.HasChanges("swagger", "url", "type", "imports")
+}
+
 func resourceBackendCreate(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
 	c, err := m.(*ProviderState).GetClient()
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	orgId := d.Get("org_id").(string)
-	name := d.Get("name").(string)
-	file := d.Get("swagger").(string)
-
-	backend, err := c.CreateBackend(orgId, name, "swagger", file)
+	backend, ty, err := importBackend(ctx, m, c, d)
 	if err != nil {
 		diags = append(diags, diag.FromErr(err)...)
 		return diags
 	}
+	d.Set("type", ty)
+	setBackendSpec(d)
 
 	// doing an update with the writable fields that might be set
 	// in the configuration.
@@ -88,7 +201,52 @@ func resourceBackendRead(ctx context.Context, d *schema.ResourceData, m interfac
 		return diags
 	}
 	flattenBackend(backend, d)
+	diags = append(diags, readBackendSpec(c, d, backend)...)
+
+	return diags
+}
 
+// What is done with a spec changed on the server.
+const (
+	reimportOnDrift = "reimport"
+	reportOnDrift   = "report"
+)
+
+// readBackendSpec compares the spec kept by the server with the configured one, warning when
+// a backend was changed elsewhere (e.g. in the UI). Unless only reported (see 'on_drift'), it
+// is imported again on the next apply: a change of a single operation in the UI replaces the whole backend.
+// The specs are compared once reformatted, see 'spec_hash'.
+// The specs imported from an url, or with the files of a WSDL, are not compared.
+func readBackendSpec(c *client.Client, d *schema.ResourceData, backend *client.Backend) (diags diag.Diagnostics) {
+	if !backend.HasOriginalDefinition || d.Get("url") != "" || len(d.Get("imports").(map[string]interface{})) > 0 {
+		return diags
+	}
+	known := d.Get("spec_hash").(string)
+	if known == "" {
+		// imported before the spec was hashed, see customizeBackend.
+		return diags
+	}
+	original, err := downloadBackendSpec(c, backend.Id)
+	if err != nil {
+		log.Printf("[WARN] cannot download the spec of backend %s, its changes are not detected: %v", backend.Id, err)
+		return diags
+	}
+	if hash := hashCanonicalSpec(original); hash != known {
+		if d.Get("on_drift") == reportOnDrift {
+			return warn(diags, "the spec of backend %s (%s) was changed on the server", d.Get("name"), backend.Id)
+		}
+		diags = warn(diags, "the spec of backend %s (%s) was changed on the server: the next apply imports it again as a new backend, "+
+			"moving its frontends to it (see 'on_drift')", d.Get("name"), backend.Id)
+		d.Set("swagger", hashSpec(original))
+		d.Set("spec_hash", hash)
+		// for the plan to show what changed.
+		if ops, err := specOperations(original); err == nil {
+			d.Set("operations", ops)
+		}
+		if info, err := parseSpec(original); err == nil {
+			d.Set("spec_info", info.flatten())
+		}
+	}
 	return diags
 }
 
@@ -98,6 +256,13 @@ func resourceBackendUpdate(ctx context.Context, d *schema.ResourceData, m interf
 		return diag.FromErr(err)
 	}
 
+	if backendSpecChanged(d) && !d.IsNewResource() {
+		diags = append(diags, replaceBackend(ctx, m, c, d)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
 	backend := &client.Backend{}
 	expandBackend(d, backend)
 
@@ -169,3 +334,232 @@ func expandBackend(d *schema.ResourceData, backend *client.Backend) {
 	backend.Properties = d.Get("properties").(map[string]interface{})
 	backend.Models = deserMap(d.Get("models").(string))
 }
+
+// replaceBackend imports the new spec as a new backend, and moves the frontends
+// from the old one onto it. The methods are matched by name: the replacement fails,
+// keeping the old backend, when a method the frontends route to is not in the new spec.
+// Should a frontend fail to be moved, the ones already moved are moved back and the
+// new backend is deleted.
+// The frontends are unpublished while moved: the ones that forbid it fail their own plan,
+// as their 'api_id' is unknown until the backend is replaced (see customizeFrontend).
+func replaceBackend(ctx context.Context, m interface{}, c *client.Client, d *schema.ResourceData) (diags diag.Diagnostics) {
+	oldId := d.Id()
+	frontends, err := listFrontends(c)
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+	var users []*client.Frontend
+	for i := range frontends {
+		if !usesBackend(&frontends[i], oldId) {
+			continue
+		}
+		if frontends[i].Retired {
+			// a retired frontend cannot be changed but unpublished, and would have to be published again.
+			d.Partial(true)
+			return diag.Errorf("the api %s is retired, it cannot be moved to the new backend: unpublish or delete it first", frontends[i].Name)
+		}
+		users = append(users, &frontends[i])
+	}
+
+	backend, ty, err := importBackend(ctx, m, c, d)
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+	var moved []movedFrontend
+	fail := func(err error) diag.Diagnostics {
+		// the state keeps the old backend.
+		d.Partial(true)
+		diags = append(diags, diag.FromErr(err)...)
+		for i := len(moved) - 1; i >= 0; i-- {
+			if err := moved[i].moveBack(c); err != nil {
+				diags = warn(diags, "moving the api %s back to the previous backend failed: %v", moved[i].frontend.Name, err)
+				return warn(diags, "the new backend %s is kept, as frontends still use it", backend.Id)
+			}
+		}
+		if err := c.DeleteBackend(backend.Id); err != nil {
+			diags = warn(diags, "the new backend %s could not be deleted: %v", backend.Id, err)
+		}
+		return diags
+	}
+
+	remap, err := backendMethodRemap(c, oldId, backend.Id, users...)
+	if err != nil {
+		return fail(err)
+	}
+
+	for _, frontend := range users {
+		m := movedFrontend{frontend: frontend, from: oldId, to: backend.Id, remap: remap}
+		m.state = frontendState(frontend, "", time.Time{})
+		if m.state == pending {
+			m.state = published
+		}
+		m.retirement = retirementDateOf(frontend)
+		if m.state != unpublished {
+			diags = append(diags, adaptStates(c, unpublished, time.Time{}, frontend)...)
+			if diags.HasError() {
+				return fail(fmt.Errorf("unpublishing the api %s failed", frontend.Name))
+			}
+			diags = warn(diags, "The api %s has been temporarily unpublished to move it to the new backend", frontend.Name)
+		}
+		repointFrontend(frontend, oldId, backend.Id, remap)
+		if err = c.UpdateFrontend(frontend); err != nil {
+			repointFrontend(frontend, backend.Id, oldId, reverse(remap))
+			adaptStates(c, m.state, m.retirement, frontend)
+			return fail(fmt.Errorf("moving the api %s to the new backend failed: %w", frontend.Name, err))
+		}
+		moved = append(moved, m)
+		diags = append(diags, adaptStates(c, m.state, m.retirement, frontend)...)
+		if diags.HasError() {
+			return fail(fmt.Errorf("restoring the state of the api %s failed", frontend.Name))
+		}
+	}
+
+	if err = c.DeleteBackend(oldId); err != nil {
+		diags = warn(diags, "the previous backend %s could not be deleted: %v", oldId, err)
+	}
+	flattenBackend(backend, d)
+	d.Set("type", ty)
+	setBackendSpec(d)
+	return diags
+}
+
+// A frontend moved to a new backend, with what is needed to move it back.
+type movedFrontend struct {
+	frontend   *client.Frontend
+	from, to   string
+	remap      map[string]string
+	state      string
+	retirement time.Time
+}
+
+func (m *movedFrontend) moveBack(c *client.Client) error {
+	if diags := adaptStates(c, unpublished, time.Time{}, m.frontend); diags.HasError() {
+		return fmt.Errorf("cannot unpublish it")
+	}
+	repointFrontend(m.frontend, m.to, m.from, reverse(m.remap))
+	if err := c.UpdateFrontend(m.frontend); err != nil {
+		return err
+	}
+	if diags := adaptStates(c, m.state, m.retirement, m.frontend); diags.HasError() {
+		return fmt.Errorf("cannot bring it back to %s", m.state)
+	}
+	return nil
+}
+
+// backendMethodRemap maps the methods of the backend 'from' onto the ones of the same name
+// of the backend 'to'. The methods the frontends route to must all be found.
+func backendMethodRemap(c *client.Client, from, to string, frontends ...*client.Frontend) (map[string]string, error) {
+	methods, err := backendMethodIdsByName(c, to)
+	if err != nil {
+		return nil, err
+	}
+	oldMethods, err := listBackendMethods(c, from)
+	if err != nil {
+		return nil, err
+	}
+	remap := map[string]string{}
+	names := map[string]string{}
+	for _, m := range oldMethods {
+		names[m.Id] = m.Name
+		if id, ok := methods[m.Name]; ok {
+			remap[m.Id] = id
+		}
+	}
+	var missing []string
+	for _, frontend := range frontends {
+		for _, p := range frontend.OutboundProfiles {
+			if p.ApiId != from || p.ApiMethodId == "" {
+				continue
+			}
+			if _, ok := remap[p.ApiMethodId]; !ok {
+				missing = append(missing, fmt.Sprintf("%s (api %s)", names[p.ApiMethodId], frontend.Name))
+			}
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("the frontends route to methods missing from the new spec: %s", strings.Join(missing, ", "))
+	}
+	return remap, nil
+}
+
+func reverse(m map[string]string) map[string]string {
+	r := make(map[string]string, len(m))
+	for k, v := range m {
+		r[v] = k
+	}
+	return r
+}
+
+// setBackendSpec sets what is read from the configured spec.
+// A spec imported from an url is not read.
+func setBackendSpec(d *schema.ResourceData) {
+	ops := map[string]interface{}{}
+	var info []interface{}
+	hash := ""
+	if spec, ok := d.GetOk("swagger"); ok {
+		hash = hashCanonicalSpec(spec.(string))
+		ops, _ = specOperations(spec.(string))
+		if i, err := parseSpec(spec.(string)); err == nil {
+			info = i.flatten()
+		}
+	}
+	d.Set("spec_hash", hash)
+	d.Set("operations", ops)
+	d.Set("spec_info", info)
+}
+
+func usesBackend(frontend *client.Frontend, backendId string) bool {
+	if frontend.ApiId == backendId {
+		return true
+	}
+	for _, p := range frontend.OutboundProfiles {
+		if p.ApiId == backendId {
+			return true
+		}
+	}
+	for _, p := range frontend.ServiceProfiles {
+		if p.ApiId == backendId {
+			return true
+		}
+	}
+	return false
+}
+
+// repointFrontend makes the frontend use the backend 'to' instead of 'from',
+// its methods being remapped onto the ones of the same name.
+func repointFrontend(frontend *client.Frontend, from, to string, remap map[string]string) {
+	if frontend.ApiId == from {
+		frontend.ApiId = to
+	}
+	for k, p := range frontend.OutboundProfiles {
+		if p.ApiId != from {
+			continue
+		}
+		p.ApiId = to
+		if p.ApiMethodId != "" {
+			p.ApiMethodId = remap[p.ApiMethodId]
+		}
+		frontend.OutboundProfiles[k] = p
+	}
+	for k, p := range frontend.ServiceProfiles {
+		if p.ApiId == from {
+			p.ApiId = to
+			frontend.ServiceProfiles[k] = p
+		}
+	}
+}
+
+func backendMethodIdsByName(c *client.Client, backendId string) (map[string]string, error) {
+	methods, err := listBackendMethods(c, backendId)
+	if err != nil {
+		return nil, err
+	}
+	r := make(map[string]string, len(methods))
+	for _, m := range methods {
+		r[m.Name] = m.Id
+	}
+	return r, nil
+}
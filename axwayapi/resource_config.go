@@ -2,6 +2,7 @@ package axwayapi
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -33,10 +34,10 @@ var TFConfigSchema = schemaMap{
 	"email_bounce_address":                inOut(_string()),
 	"email_from":                          inOut(_string()),
 	"fault_handlers_enabled":              inOut(_bool()),
-	"global_fault_handler_policy":         inOut(_string()),
+	"global_fault_handler_policy":         desc(inOut(_string()), "The key or the name of a 'faulthandler' policy"),
 	"global_policies_enabled":             inOut(_bool()),
-	"global_request_policy":               inOut(_string()),
-	"global_response_policy":              inOut(_string()),
+	"global_request_policy":               desc(inOut(_string()), "The key or the name of a 'global' policy"),
+	"global_response_policy":              desc(inOut(_string()), "The key or the name of a 'global' policy"),
 	"is_api_portal_configured":            inOut(_bool()),
 	"is_trial":                            inOut(_bool()),
 	"login_name_regex":                    inOut(_string()),
@@ -84,20 +85,71 @@ var TFConfigSchema = schemaMap{
 }
 
 // This whole resource works slightly differently than regular ones.
-// The CREATE part does in fact only read the configuration from the gateway
-// The READ and UPDATE works as expected
-// The DELETE is a no-op.
+// The CREATE part reads the configuration from the gateway, and applies
+// the attributes that are set in the terraform configuration onto it.
+// The UPDATE does the same: attributes left out of the terraform configuration
+// are not managed, so several stacks may each manage their own settings.
+// The READ works as expected.
+// The DELETE leaves the configuration as it is, unless 'restore_on_destroy' is set.
 // It is so because the config is not in itself a resource to be created.
 // In a regular workflow, it should be imported.
 // But just to have a nice one-step workflow, we simulate creation and deletion.
+// With 'restore_on_destroy', the configuration and default quotas found on
+// creation are kept in the private state, and written back on deletion.
 func resourceConfig() *schema.Resource {
-	return &schema.Resource{
-		Schema:        TFConfigSchema,
+	s := schemaMap{
+		"restore_on_destroy": desc(optional(_bool(), false),
+			`On destroy, restore the whole configuration and the default quotas as they were before the creation of this resource.
+			Settings changed since by other means are reverted as well.
+			The configuration found on creation is kept in the private state of the resource, not shown in its attributes.`),
+	}
+	for k, v := range TFConfigSchema {
+		s[k] = v
+	}
+	return resourceConfigSubset(s)
+}
+
+// The password policy part of the configuration, as a resource of its own.
+func resourceConfigPasswordPolicy() *schema.Resource {
+	return resourceConfigSubset(TFConfigSchema.only(
+		"minimum_password_length",
+		"password_expiry_enabled",
+		"password_lifetime_days",
+		"change_password_on_first_login",
+		"reset_password_enabled",
+		"lock_user_account",
+	))
+}
+
+// The global policies part of the configuration, as a resource of its own.
+func resourceConfigGlobalPolicies() *schema.Resource {
+	return resourceConfigSubset(TFConfigSchema.only(
+		"global_policies_enabled",
+		"global_request_policy",
+		"global_response_policy",
+		"fault_handlers_enabled",
+		"global_fault_handler_policy",
+	))
+}
+
+func resourceConfigSubset(s schemaMap) *schema.Resource {
+	r := &schema.Resource{
+		Schema:        s,
 		CreateContext: resourceConfigCreate,
 		ReadContext:   resourceConfigRead,
 		UpdateContext: resourceConfigUpdate,
 		DeleteContext: resourceConfigDelete,
 	}
+	quotas := []string{}
+	for _, k := range []string{"system_default_quota", "application_default_quota"} {
+		if _, ok := s[k]; ok {
+			quotas = append(quotas, k)
+		}
+	}
+	if len(quotas) > 0 {
+		r.CustomizeDiff = customizeRestrictions(quotas...)
+	}
+	return r
 }
 
 func resourceConfigCreate(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
@@ -113,8 +165,21 @@ func resourceConfigCreate(ctx context.Context, d *schema.ResourceData, m interfa
 		return diags
 	}
 
+	if d.Get("restore_on_destroy") == true {
+		snapshot, err := takeConfigSnapshot(c, config)
+		if err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+			return diags
+		}
+		setPrivate(ctx, "snapshot", snapshot)
+	}
+
 	// apply the tf configuration on the config read from server
-	expandConfig(d, config, false)
+	expandConfig(d, config)
+	if err = resolvePolicies(newPolicyIndex(c), configPolicies(config)); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
 	// The config object is updated with the latest configs
 	err = c.UpdateConfig(config)
 	if err != nil {
@@ -125,6 +190,7 @@ func resourceConfigCreate(ctx context.Context, d *schema.ResourceData, m interfa
 	diags = append(diags, syncDefaultQuota(d, c)...)
 	// update our state from the freshest config read from server.
 
+	nameConfigPolicies(c, config, d)
 	flattenConfig(config, d)
 
 	d.SetId(fmt.Sprintf("%s/config", c.HostURL))
@@ -144,27 +210,37 @@ func resourceConfigRead(ctx context.Context, d *schema.ResourceData, m interface
 		return diags
 	}
 	// apply the read conf onto our state
+	nameConfigPolicies(c, config, d)
 	flattenConfig(config, d)
 
+	if !hasAttribute(d, "application_default_quota") {
+		// a scoped resource, without the quotas.
+		return diags
+	}
+
 	// Reading the quota
-	defapp, err := c.GetQuota("00000000-0000-0000-0000-000000000001")
+	defapp, err := c.GetQuota(applicationDefaultQuotaId)
 	if err != nil {
 		diags = append(diags, diag.FromErr(err)...)
 		return diags
 	}
-	err = d.Set("application_default_quota", flattenQuota(defapp))
+	prior := d.Get("application_default_quota.0.restriction")
+	nameRestrictionMethods(c, defapp.Restrictions, prior)
+	err = d.Set("application_default_quota", flattenQuota(defapp, prior))
 	if err != nil {
 		diags = append(diags, diag.FromErr(err)...)
 		return diags
 	}
 
 	// Reading the quota
-	defsys, err := c.GetQuota("00000000-0000-0000-0000-000000000000")
+	defsys, err := c.GetQuota(systemDefaultQuotaId)
 	if err != nil {
 		diags = append(diags, diag.FromErr(err)...)
 		return diags
 	}
-	err = d.Set("system_default_quota", flattenQuota(defsys))
+	prior = d.Get("system_default_quota.0.restriction")
+	nameRestrictionMethods(c, defsys.Restrictions, prior)
+	err = d.Set("system_default_quota", flattenQuota(defsys, prior))
 	if err != nil {
 		diags = append(diags, diag.FromErr(err)...)
 		return diags
@@ -172,10 +248,10 @@ func resourceConfigRead(ctx context.Context, d *schema.ResourceData, m interface
 
 	return diags
 }
-func flattenQuota(quota *client.Quota) []flattenMap {
+func flattenQuota(quota *client.Quota, prior interface{}) []flattenMap {
 	return []flattenMap{{
 		"id":          quota.Id,
-		"restriction": flattenRestriction(quota.Restrictions),
+		"restriction": flattenRestriction(quota.Restrictions, prior),
 	}}
 }
 
@@ -185,10 +261,18 @@ func resourceConfigUpdate(ctx context.Context, d *schema.ResourceData, m interfa
 		return diag.FromErr(err)
 	}
 
-	// Start from blank config object
-	config := &client.Config{}
-	// forcibly apply our state onto that object
-	expandConfig(d, config, true)
+	// read config from API Gateway, so that unmanaged settings are left as is.
+	config, err := c.GetConfig()
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+	// apply the tf configuration on the config read from server
+	expandConfig(d, config)
+	if err = resolvePolicies(newPolicyIndex(c), configPolicies(config)); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
 	// update the server with this new state
 	err = c.UpdateConfig(config)
 	if err != nil {
@@ -199,116 +283,177 @@ func resourceConfigUpdate(ctx context.Context, d *schema.ResourceData, m interfa
 	diags = append(diags, syncDefaultQuota(d, c)...)
 
 	// update our state from the freshest config read from server.
+	nameConfigPolicies(c, config, d)
 	flattenConfig(config, d)
 
 	d.Set("last_updated", time.Now().Format(time.RFC850))
 	return diags
 }
 
+// Only the default quotas present in the configuration are managed.
 func syncDefaultQuota(d *schema.ResourceData, c *client.Client) (diags diag.Diagnostics) {
+	for _, q := range []struct{ key, id string }{
+		{"system_default_quota", systemDefaultQuotaId},
+		{"application_default_quota", applicationDefaultQuotaId},
+	} {
+		if !isConfigured(d, q.key) {
+			continue
+		}
+		quota, err := c.GetQuota(q.id)
+		if err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+			return diags
+		}
+		if v, ok := d.GetOk(q.key); ok {
+			wanted := &client.Quota{}
+			expandQuota2(v, "", wanted)
+			if err := resolveRestrictionMethods(c, wanted.Restrictions); err != nil {
+				diags = append(diags, diag.FromErr(err)...)
+				return diags
+			}
+			quota.Restrictions = wanted.Restrictions
+		} else {
+			quota.Restrictions = []client.Constraint{}
+		}
+		err = c.UpdateQuota(quota)
+		if err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+			return diags
+		}
+		prior := d.Get(q.key + ".0.restriction")
+		nameRestrictionMethods(c, quota.Restrictions, prior)
+		d.Set(q.key, flattenQuota(quota, prior))
+	}
 
-	defsys, err := c.GetQuota("00000000-0000-0000-0000-000000000000")
-	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
+	return diags
+}
+
+func resourceConfigDelete(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	if d.Get("restore_on_destroy") != true {
 		return diags
 	}
-	if q, ok := d.GetOk("system_default_quota"); ok {
-		quota := &client.Quota{}
-		expandQuota2(q, "", quota)
-		defsys.Restrictions = quota.Restrictions
-	} else {
-		defsys.Restrictions = []client.Constraint{}
+	snapshot := getPrivate(ctx, "snapshot")
+	if snapshot == "" {
+		// restore_on_destroy was set after the creation.
+		return warn(diags, "no snapshot of the configuration was taken on creation: nothing to restore")
 	}
-	err = c.UpdateQuota(defsys)
+
+	c, err := m.(*ProviderState).GetClient()
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
-		return diags
+		return diag.FromErr(err)
 	}
-	if diags.HasError() {
-		return diags
+	if err = restoreConfigSnapshot(c, snapshot); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
 	}
-	d.Set("system_default_quota", flattenQuota(defsys))
+	return diags
+}
 
-	defapp, err := c.GetQuota("00000000-0000-0000-0000-000000000001")
-	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
-		return diags
+// The configuration and default quotas, as found before terraform changed them.
+type configSnapshot struct {
+	Config           *client.Config `json:"config"`
+	SystemQuota      *client.Quota  `json:"systemDefaultQuota"`
+	ApplicationQuota *client.Quota  `json:"applicationDefaultQuota"`
+}
+
+func takeConfigSnapshot(c *client.Client, config *client.Config) (string, error) {
+	var err error
+	snapshot := configSnapshot{Config: config}
+	if snapshot.SystemQuota, err = c.GetQuota(systemDefaultQuotaId); err != nil {
+		return "", err
 	}
-	if q, ok := d.GetOk("application_default_quota"); ok {
-		quota := &client.Quota{}
-		expandQuota2(q, "", quota)
-		defapp.Restrictions = quota.Restrictions
-	} else {
-		defapp.Restrictions = []client.Constraint{}
+	if snapshot.ApplicationQuota, err = c.GetQuota(applicationDefaultQuotaId); err != nil {
+		return "", err
 	}
-	err = c.UpdateQuota(defapp)
+	b, err := json.Marshal(snapshot)
 	if err != nil {
-		diags = append(diags, diag.FromErr(err)...)
-		return diags
-	}
-	if diags.HasError() {
-		return diags
+		return "", err
 	}
-	d.Set("application_default_quota", flattenQuota(defapp))
+	return string(b), nil
+}
 
-	return diags
+func restoreConfigSnapshot(c *client.Client, s string) error {
+	snapshot := configSnapshot{}
+	if err := json.Unmarshal([]byte(s), &snapshot); err != nil {
+		return fmt.Errorf("invalid configuration snapshot: %w", err)
+	}
+	if err := c.UpdateConfig(snapshot.Config); err != nil {
+		return err
+	}
+	if err := c.UpdateQuota(snapshot.SystemQuota); err != nil {
+		return err
+	}
+	return c.UpdateQuota(snapshot.ApplicationQuota)
 }
 
-func resourceConfigDelete(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
-	return diags
+// nameConfigPolicies keeps the global policies referenced by name in the
+// configuration, instead of their keys.
+func nameConfigPolicies(c *client.Client, config *client.Config, d *schema.ResourceData) {
+	namePolicies(newPolicyIndex(c), configPolicies(config), func(attr string) interface{} {
+		if !hasAttribute(d, attr) {
+			return nil
+		}
+		return d.Get(attr)
+	})
 }
 
 // There was a version based on reflection, but this is easier to maintain.
 func flattenConfig(axconfig *client.Config, d *schema.ResourceData) {
-	d.Set("registration_enabled", axconfig.RegistrationEnabled)
-	d.Set("reg_token_email_enabled", axconfig.RegTokenEmailEnabled)
-	d.Set("api_import_timeout", axconfig.ApiImportTimeout)
-	d.Set("is_trial", axconfig.IsTrial)
-	d.Set("promote_api_via_policy", axconfig.PromoteApiViaPolicy)
-	d.Set("system_o_auth_scopes_enabled", axconfig.SystemOAuthScopesEnabled)
-	d.Set("oadmin_self_service_enabled", axconfig.OadminSelfServiceEnabled)
-	d.Set("product_version", axconfig.ProductVersion)
-	d.Set("portal_name", axconfig.PortalName)
-	d.Set("global_response_policy", axconfig.GlobalResponsePolicy)
-	d.Set("auto_approve_applications", axconfig.AutoApproveApplications)
-	d.Set("global_request_policy", axconfig.GlobalRequestPolicy)
-	d.Set("auto_approve_user_registration", axconfig.AutoApproveUserRegistration)
-	d.Set("delegate_application_administration", axconfig.DelegateApplicationAdministration)
-	d.Set("api_default_virtual_host", axconfig.ApiDefaultVirtualHost)
-	d.Set("api_routing_key_location", axconfig.ApiRoutingKeyLocation)
-	d.Set("application_scope_restrictions", axconfig.ApplicationScopeRestrictions)
-	d.Set("base_o_auth", axconfig.BaseOAuth)
-	d.Set("email_bounce_address", axconfig.EmailBounceAddress)
-	d.Set("advisory_banner_enabled", axconfig.AdvisoryBannerEnabled)
-	d.Set("user_name_regex", axconfig.UserNameRegex)
-	d.Set("api_import_mime_validation", axconfig.ApiImportMimeValidation)
-	d.Set("session_idle_timeout_millis", axconfig.SessionIdleTimeout)
-	d.Set("is_api_portal_configured", axconfig.IsApiPortalConfigured)
-	d.Set("change_password_on_first_login", axconfig.ChangePasswordOnFirstLogin)
-	d.Set("session_timeout_millis", axconfig.SessionTimeout)
-	d.Set("email_from", axconfig.EmailFrom)
-	d.Set("api_routing_key_enabled", axconfig.ApiRoutingKeyEnabled)
-	d.Set("login_response_time", axconfig.LoginResponseTime)
-	d.Set("server_certificate_verification", axconfig.ServerCertificateVerification)
-	d.Set("reset_password_enabled", axconfig.ResetPasswordEnabled)
-	d.Set("advisory_banner_text", axconfig.AdvisoryBannerText)
-	d.Set("api_import_editable", axconfig.ApiImportEditable)
-	d.Set("api_portal_hostname", axconfig.ApiPortalHostname)
-	d.Set("api_portal_name", axconfig.ApiPortalName)
-	d.Set("fault_handlers_enabled", axconfig.FaultHandlersEnabled)
-	d.Set("architecture", axconfig.Architecture)
-	d.Set("strict_certificate_checking", axconfig.StrictCertificateChecking)
-	d.Set("global_policies_enabled", axconfig.GlobalPoliciesEnabled)
-	d.Set("minimum_password_length", axconfig.MinimumPasswordLength)
-	d.Set("password_expiry_enabled", axconfig.PasswordExpiryEnabled)
-	d.Set("os", axconfig.Os)
-	d.Set("login_name_regex", axconfig.LoginNameRegex)
-	d.Set("default_trial_duration", axconfig.DefaultTrialDuration)
-	d.Set("global_fault_handler_policy", axconfig.GlobalFaultHandlerPolicy)
-	d.Set("password_lifetime_days", axconfig.PasswordLifetimeDays)
-	d.Set("delegate_user_administration", axconfig.DelegateUserAdministration)
-	d.Set("portal_hostname", axconfig.PortalHostname)
-	d.Set("lock_user_account", flattenLua(&axconfig.LockUserAccount))
+	ty := d.GetRawConfig().Type()
+	set := func(key string, value interface{}) {
+		// the scoped resources hold only a subset of the attributes.
+		if ty.HasAttribute(key) {
+			d.Set(key, value)
+		}
+	}
+	set("registration_enabled", axconfig.RegistrationEnabled)
+	set("reg_token_email_enabled", axconfig.RegTokenEmailEnabled)
+	set("api_import_timeout", axconfig.ApiImportTimeout)
+	set("is_trial", axconfig.IsTrial)
+	set("promote_api_via_policy", axconfig.PromoteApiViaPolicy)
+	set("system_o_auth_scopes_enabled", axconfig.SystemOAuthScopesEnabled)
+	set("oadmin_self_service_enabled", axconfig.OadminSelfServiceEnabled)
+	set("product_version", axconfig.ProductVersion)
+	set("portal_name", axconfig.PortalName)
+	set("global_response_policy", axconfig.GlobalResponsePolicy)
+	set("auto_approve_applications", axconfig.AutoApproveApplications)
+	set("global_request_policy", axconfig.GlobalRequestPolicy)
+	set("auto_approve_user_registration", axconfig.AutoApproveUserRegistration)
+	set("delegate_application_administration", axconfig.DelegateApplicationAdministration)
+	set("api_default_virtual_host", axconfig.ApiDefaultVirtualHost)
+	set("api_routing_key_location", axconfig.ApiRoutingKeyLocation)
+	set("application_scope_restrictions", axconfig.ApplicationScopeRestrictions)
+	set("base_o_auth", axconfig.BaseOAuth)
+	set("email_bounce_address", axconfig.EmailBounceAddress)
+	set("advisory_banner_enabled", axconfig.AdvisoryBannerEnabled)
+	set("user_name_regex", axconfig.UserNameRegex)
+	set("api_import_mime_validation", axconfig.ApiImportMimeValidation)
+	set("session_idle_timeout_millis", axconfig.SessionIdleTimeout)
+	set("is_api_portal_configured", axconfig.IsApiPortalConfigured)
+	set("change_password_on_first_login", axconfig.ChangePasswordOnFirstLogin)
+	set("session_timeout_millis", axconfig.SessionTimeout)
+	set("email_from", axconfig.EmailFrom)
+	set("api_routing_key_enabled", axconfig.ApiRoutingKeyEnabled)
+	set("login_response_time", axconfig.LoginResponseTime)
+	set("server_certificate_verification", axconfig.ServerCertificateVerification)
+	set("reset_password_enabled", axconfig.ResetPasswordEnabled)
+	set("advisory_banner_text", axconfig.AdvisoryBannerText)
+	set("api_import_editable", axconfig.ApiImportEditable)
+	set("api_portal_hostname", axconfig.ApiPortalHostname)
+	set("api_portal_name", axconfig.ApiPortalName)
+	set("fault_handlers_enabled", axconfig.FaultHandlersEnabled)
+	set("architecture", axconfig.Architecture)
+	set("strict_certificate_checking", axconfig.StrictCertificateChecking)
+	set("global_policies_enabled", axconfig.GlobalPoliciesEnabled)
+	set("minimum_password_length", axconfig.MinimumPasswordLength)
+	set("password_expiry_enabled", axconfig.PasswordExpiryEnabled)
+	set("os", axconfig.Os)
+	set("login_name_regex", axconfig.LoginNameRegex)
+	set("default_trial_duration", axconfig.DefaultTrialDuration)
+	set("global_fault_handler_policy", axconfig.GlobalFaultHandlerPolicy)
+	set("password_lifetime_days", axconfig.PasswordLifetimeDays)
+	set("delegate_user_administration", axconfig.DelegateUserAdministration)
+	set("portal_hostname", axconfig.PortalHostname)
+	set("lock_user_account", flattenLua(&axconfig.LockUserAccount))
 }
 
 func flattenLua(lua *client.LockUserAccount) (res []map[string]interface{}) {
@@ -323,171 +468,198 @@ func flattenLua(lua *client.LockUserAccount) (res []map[string]interface{}) {
 }
 
 // Tedious but much easier to maintain and understand...
-func expandConfig(tfconfig *schema.ResourceData, axconfig *client.Config, full bool) {
+// Only the attributes set in the configuration are applied.
+func expandConfig(tfconfig *schema.ResourceData, axconfig *client.Config) {
 	raw := tfconfig.GetRawConfig().AsValueMap()
-	if full || !raw["registration_enabled"].IsNull() {
+	isSet := func(key string) bool {
+		v, ok := raw[key]
+		return ok && !v.IsNull()
+	}
+	if isSet("registration_enabled") {
 		axconfig.RegistrationEnabled = tfconfig.Get("registration_enabled").(bool)
 	}
-	if full || !raw["reg_token_email_enabled"].IsNull() {
+	if isSet("reg_token_email_enabled") {
 		axconfig.RegTokenEmailEnabled = tfconfig.Get("reg_token_email_enabled").(bool)
 	}
-	if full || !raw["api_import_timeout"].IsNull() {
+	if isSet("api_import_timeout") {
 		axconfig.ApiImportTimeout = tfconfig.Get("api_import_timeout").(int)
 	}
-	if full || !raw["is_trial"].IsNull() {
+	if isSet("is_trial") {
 		axconfig.IsTrial = tfconfig.Get("is_trial").(bool)
 	}
-	if full || !raw["promote_api_via_policy"].IsNull() {
+	if isSet("promote_api_via_policy") {
 		axconfig.PromoteApiViaPolicy = tfconfig.Get("promote_api_via_policy").(bool)
 	}
-	if full || !raw["system_o_auth_scopes_enabled"].IsNull() {
+	if isSet("system_o_auth_scopes_enabled") {
 		axconfig.SystemOAuthScopesEnabled = tfconfig.Get("system_o_auth_scopes_enabled").(bool)
 	}
-	if full || !raw["oadmin_self_service_enabled"].IsNull() {
+	if isSet("oadmin_self_service_enabled") {
 		axconfig.OadminSelfServiceEnabled = tfconfig.Get("oadmin_self_service_enabled").(bool)
 	}
-	if full || !raw["product_version"].IsNull() {
+	if isSet("product_version") {
 		axconfig.ProductVersion = tfconfig.Get("product_version").(string)
 	}
-	if full || !raw["portal_name"].IsNull() {
+	if isSet("portal_name") {
 		axconfig.PortalName = tfconfig.Get("portal_name").(string)
 	}
-	if full || !raw["global_response_policy"].IsNull() {
+	if isSet("global_response_policy") {
 		axconfig.GlobalResponsePolicy = tfconfig.Get("global_response_policy").(string)
 	}
-	if full || !raw["auto_approve_applications"].IsNull() {
+	if isSet("auto_approve_applications") {
 		axconfig.AutoApproveApplications = tfconfig.Get("auto_approve_applications").(bool)
 	}
-	if full || !raw["global_request_policy"].IsNull() {
+	if isSet("global_request_policy") {
 		axconfig.GlobalRequestPolicy = tfconfig.Get("global_request_policy").(string)
 	}
-	if full || !raw["auto_approve_user_registration"].IsNull() {
+	if isSet("auto_approve_user_registration") {
 		axconfig.AutoApproveUserRegistration = tfconfig.Get("auto_approve_user_registration").(bool)
 	}
-	if full || !raw["delegate_application_administration"].IsNull() {
+	if isSet("delegate_application_administration") {
 		axconfig.DelegateApplicationAdministration = tfconfig.Get("delegate_application_administration").(bool)
 	}
-	if full || !raw["api_default_virtual_host"].IsNull() {
+	if isSet("api_default_virtual_host") {
 		axconfig.ApiDefaultVirtualHost = tfconfig.Get("api_default_virtual_host").(string)
 	}
-	if full || !raw["api_routing_key_location"].IsNull() {
+	if isSet("api_routing_key_location") {
 		axconfig.ApiRoutingKeyLocation = tfconfig.Get("api_routing_key_location").(string)
 	}
-	if full || !raw["application_scope_restrictions"].IsNull() {
+	if isSet("application_scope_restrictions") {
 		axconfig.ApplicationScopeRestrictions = tfconfig.Get("application_scope_restrictions").(bool)
 	}
-	if full || !raw["base_o_auth"].IsNull() {
+	if isSet("base_o_auth") {
 		axconfig.BaseOAuth = tfconfig.Get("base_o_auth").(bool)
 	}
-	if full || !raw["email_bounce_address"].IsNull() {
+	if isSet("email_bounce_address") {
 		axconfig.EmailBounceAddress = tfconfig.Get("email_bounce_address").(string)
 	}
-	if full || !raw["advisory_banner_enabled"].IsNull() {
+	if isSet("advisory_banner_enabled") {
 		axconfig.AdvisoryBannerEnabled = tfconfig.Get("advisory_banner_enabled").(bool)
 	}
-	if full || !raw["user_name_regex"].IsNull() {
+	if isSet("user_name_regex") {
 		axconfig.UserNameRegex = tfconfig.Get("user_name_regex").(string)
 	}
-	if full || !raw["api_import_mime_validation"].IsNull() {
+	if isSet("api_import_mime_validation") {
 		axconfig.ApiImportMimeValidation = tfconfig.Get("api_import_mime_validation").(bool)
 	}
-	if full || !raw["session_idle_timeout_millis"].IsNull() {
+	if isSet("session_idle_timeout_millis") {
 		axconfig.SessionIdleTimeout = tfconfig.Get("session_idle_timeout_millis").(int)
 	}
-	if full || !raw["is_api_portal_configured"].IsNull() {
+	if isSet("is_api_portal_configured") {
 		axconfig.IsApiPortalConfigured = tfconfig.Get("is_api_portal_configured").(bool)
 	}
-	if full || !raw["change_password_on_first_login"].IsNull() {
+	if isSet("change_password_on_first_login") {
 		axconfig.ChangePasswordOnFirstLogin = tfconfig.Get("change_password_on_first_login").(bool)
 	}
-	if full || !raw["session_timeout_millis"].IsNull() {
+	if isSet("session_timeout_millis") {
 		axconfig.SessionTimeout = tfconfig.Get("session_timeout_millis").(int)
 	}
-	if full || !raw["email_from"].IsNull() {
+	if isSet("email_from") {
 		axconfig.EmailFrom = tfconfig.Get("email_from").(string)
 	}
-	if full || !raw["api_routing_key_enabled"].IsNull() {
+	if isSet("api_routing_key_enabled") {
 		axconfig.ApiRoutingKeyEnabled = tfconfig.Get("api_routing_key_enabled").(bool)
 	}
-	if full || !raw["login_response_time"].IsNull() {
+	if isSet("login_response_time") {
 		axconfig.LoginResponseTime = tfconfig.Get("login_response_time").(int)
 	}
-	if full || !raw["server_certificate_verification"].IsNull() {
+	if isSet("server_certificate_verification") {
 		axconfig.ServerCertificateVerification = tfconfig.Get("server_certificate_verification").(bool)
 	}
-	if full || !raw["reset_password_enabled"].IsNull() {
+	if isSet("reset_password_enabled") {
 		axconfig.ResetPasswordEnabled = tfconfig.Get("reset_password_enabled").(bool)
 	}
-	if full || !raw["advisory_banner_text"].IsNull() {
+	if isSet("advisory_banner_text") {
 		axconfig.AdvisoryBannerText = tfconfig.Get("advisory_banner_text").(string)
 	}
-	if full || !raw["api_import_editable"].IsNull() {
+	if isSet("api_import_editable") {
 		axconfig.ApiImportEditable = tfconfig.Get("api_import_editable").(bool)
 	}
-	if full || !raw["api_portal_hostname"].IsNull() {
+	if isSet("api_portal_hostname") {
 		axconfig.ApiPortalHostname = tfconfig.Get("api_portal_hostname").(string)
 	}
-	if full || !raw["api_portal_name"].IsNull() {
+	if isSet("api_portal_name") {
 		axconfig.ApiPortalName = tfconfig.Get("api_portal_name").(string)
 	}
-	if full || !raw["fault_handlers_enabled"].IsNull() {
+	if isSet("fault_handlers_enabled") {
 		axconfig.FaultHandlersEnabled = tfconfig.Get("fault_handlers_enabled").(bool)
 	}
-	if full || !raw["architecture"].IsNull() {
+	if isSet("architecture") {
 		axconfig.Architecture = tfconfig.Get("architecture").(string)
 	}
-	if full || !raw["strict_certificate_checking"].IsNull() {
+	if isSet("strict_certificate_checking") {
 		axconfig.StrictCertificateChecking = tfconfig.Get("strict_certificate_checking").(bool)
 	}
-	if full || !raw["global_policies_enabled"].IsNull() {
+	if isSet("global_policies_enabled") {
 		axconfig.GlobalPoliciesEnabled = tfconfig.Get("global_policies_enabled").(bool)
 	}
-	if full || !raw["minimum_password_length"].IsNull() {
+	if isSet("minimum_password_length") {
 		axconfig.MinimumPasswordLength = tfconfig.Get("minimum_password_length").(int)
 	}
-	if full || !raw["password_expiry_enabled"].IsNull() {
+	if isSet("password_expiry_enabled") {
 		axconfig.PasswordExpiryEnabled = tfconfig.Get("password_expiry_enabled").(bool)
 	}
-	if full || !raw["os"].IsNull() {
+	if isSet("os") {
 		axconfig.Os = tfconfig.Get("os").(string)
 	}
-	if full || !raw["login_name_regex"].IsNull() {
+	if isSet("login_name_regex") {
 		axconfig.LoginNameRegex = tfconfig.Get("login_name_regex").(string)
 	}
-	if full || !raw["default_trial_duration"].IsNull() {
+	if isSet("default_trial_duration") {
 		axconfig.DefaultTrialDuration = tfconfig.Get("default_trial_duration").(int)
 	}
-	if full || !raw["global_fault_handler_policy"].IsNull() {
+	if isSet("global_fault_handler_policy") {
 		axconfig.GlobalFaultHandlerPolicy = tfconfig.Get("global_fault_handler_policy").(string)
 	}
-	if full || !raw["password_lifetime_days"].IsNull() {
+	if isSet("password_lifetime_days") {
 		axconfig.PasswordLifetimeDays = tfconfig.Get("password_lifetime_days").(int)
 	}
-	if full || !raw["delegate_user_administration"].IsNull() {
+	if isSet("delegate_user_administration") {
 		axconfig.DelegateUserAdministration = tfconfig.Get("delegate_user_administration").(bool)
 	}
-	if full || !raw["portal_hostname"].IsNull() {
+	if isSet("portal_hostname") {
 		axconfig.PortalHostname = tfconfig.Get("portal_hostname").(string)
 	}
-	if raw["lock_user_account"].LengthInt() > 0 {
+	if isSet("lock_user_account") && raw["lock_user_account"].LengthInt() > 0 {
 		lua := raw["lock_user_account"].AsValueSlice()[0].AsValueMap()
-		if full || !lua["attempts"].IsNull() {
+		if !lua["enabled"].IsNull() {
 			axconfig.LockUserAccount.Enabled = tfconfig.Get("lock_user_account.0.enabled").(bool)
 		}
-		if full || !lua["attempts"].IsNull() {
+		if !lua["attempts"].IsNull() {
 			axconfig.LockUserAccount.Attempts = tfconfig.Get("lock_user_account.0.attempts").(int)
 		}
-		if full || !lua["lock_time_period"].IsNull() {
+		if !lua["lock_time_period"].IsNull() {
 			axconfig.LockUserAccount.LockTimePeriod = tfconfig.Get("lock_user_account.0.lock_time_period").(int)
 		}
-		if full || !lua["lock_time_period_unit"].IsNull() {
+		if !lua["lock_time_period_unit"].IsNull() {
 			axconfig.LockUserAccount.LockTimePeriodUnit = tfconfig.Get("lock_user_account.0.lock_time_period_unit").(string)
 		}
-		if full || !lua["time_period"].IsNull() {
+		if !lua["time_period"].IsNull() {
 			axconfig.LockUserAccount.TimePeriod = tfconfig.Get("lock_user_account.0.time_period").(int)
 		}
-		if full || !lua["time_period_unit"].IsNull() {
+		if !lua["time_period_unit"].IsNull() {
 			axconfig.LockUserAccount.TimePeriodUnit = tfconfig.Get("lock_user_account.0.time_period_unit").(string)
 		}
 	}
 }
+
+func (s schemaMap) only(keys ...string) schemaMap {
+	r := make(schemaMap, len(keys))
+	for _, k := range keys {
+		r[k] = s[k]
+	}
+	return r
+}
+
+// hasAttribute tells whether the resource at hand has the given attribute.
+func hasAttribute(d *schema.ResourceData, key string) bool {
+	return d.GetRawConfig().Type().HasAttribute(key)
+}
+
+// isConfigured tells whether the given block is present in the configuration.
+func isConfigured(d *schema.ResourceData, key string) bool {
+	if !hasAttribute(d, key) {
+		return false
+	}
+	v := d.GetRawConfig().GetAttr(key)
+	return !v.IsNull() && v.IsKnown() && v.LengthInt() > 0
+}
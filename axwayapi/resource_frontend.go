@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"time"
 
 	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -14,7 +16,7 @@ import (
 var TFFrontendSchema = &schemaMap{
 	"id":                   readonly(_string()),
 	"org_id":               _FORCENEW(inOut(_string())),
-	"api_id":               _FORCENEW(inOut(_string())),
+	"api_id":               desc(inOut(_string()), "The backend: changing it moves the frontend to the methods of the same name of the new backend"),
 	"name":                 required(_string()),
 	"version":              inOut(_string()),
 	"api_routing_key":      inOut(_string()),
@@ -27,12 +29,27 @@ var TFFrontendSchema = &schemaMap{
 	"summary":              inOut(_string()),
 	"retired":              inOut(_bool()),
 	"expired":              inOut(_bool()),
-	"image_jpg":            inOut(_hashedString()),
-	"retirement_date":      inOut(_int()),
-	"state": desc(inOut(_string(oneOf(published, unpublished, deprecated))),
-		`Can be 'unpublished', 'published' or 'deprecated'.
+	"image_jpg":            _imageJpg(inOut(_hashedString())),
+	"image":                _image(),
+	"image_hash":           _imageHash(),
+	"image_saved":          _imageSaved(),
+	"retirement_date": desc(inOut(_string(validRFC3339)),
+		"The date the frontend is retired once deprecated, in RFC 3339, e.g. 2023-06-30T00:00:00Z"),
+	"state": desc(inOut(_string(oneOf(published, unpublished, deprecated, retired))),
+		`Can be 'unpublished', 'published', 'deprecated' or 'retired'. The server may also report 'pending',
+		while a publication awaits approval: it is then published once approved, on the next apply.
+		Retiring deprecates the frontend with its 'retirement_date', or with an immediate retirement if none:
+			it is reported retired from then on, as long as it keeps this date.
 		Published and deprecated frontends must be temporarily 
-		unpublished to apply some changes. A warning will be displayed when this occurs.`),
+		unpublished to apply some changes. A warning is displayed when this occurs,
+		and the plan fails instead when 'allow_unpublish' is false.`),
+	"method": desc(optional(_list(TFFrontendMethod)),
+		"The overrides of the profiles and documentation of single methods"),
+	"upgrade_from": desc(optional(_listMax(1, TFFrontendUpgrade)),
+		`Upgrade from an older frontend, keeping its consumers. Done once, on creation or when 'frontend_id' changes.`),
+	"allow_unpublish": desc(optional(_bool(), true),
+		`Whether the frontend may be temporarily unpublished to apply changes (see 'state').
+		When false, the plan fails instead, listing the attributes that require it.`),
 	"cors_profile":           inOut(_list(TFCorsProfile)),
 	"security_profile":       inOut(_list(TFSecurityProfile)),
 	"authentication_profile": inOut(_list(TFAuthenticationProfile)),
@@ -48,16 +65,47 @@ var TFFrontendSchema = &schemaMap{
 
 var TFCorsProfile = &schema.Resource{
 	Schema: map[string]*schema.Schema{
-		"name":                required(_string()),
-		"is_default":          required(_bool()),
-		"origins":             required(_plist(schema.TypeString)),
-		"allowed_headers":     required(_plist(schema.TypeString)),
-		"exposed_headers":     required(_plist(schema.TypeString)),
+		"name":       desc(required(_string()), "The name of the profile, unique within the frontend"),
+		"is_default": required(_bool()),
+		"origins": desc(required(_psetOf(_string(validOrigin))),
+			`'*', or origins such as "https://example.com", "https://*.example.com:8443"`),
+		"allowed_headers":     required(_psetOf(_string(validHeaderName))),
+		"exposed_headers":     required(_psetOf(_string(validHeaderName))),
 		"support_credentials": required(_bool()),
-		"max_age_seconds":     inOut(_int()),
+		"max_age_seconds": desc(inOut(_int()),
+			"When not set, the value of the server is kept. Note that 0 stands for the default of the server."),
 	},
 }
 
+var validOrigin = r(`^(\*|https?://(\*\.)?[a-zA-Z0-9]([a-zA-Z0-9.-]*[a-zA-Z0-9])?(:[0-9]{1,5})?)$`)
+
+// The header names are tokens (RFC 7230), '*' included.
+var validHeaderName = r("^[!#$%&'*+.^_`|~0-9a-zA-Z-]+$")
+
+// checkCorsProfiles rejects the profiles with the same name, as the last one
+// would silently win on the server.
+func checkCorsProfiles(profiles cty.Value) error {
+	if profiles.IsNull() || !profiles.IsKnown() {
+		return nil
+	}
+	seen := map[string]bool{}
+	for it := profiles.ElementIterator(); it.Next(); {
+		_, p := it.Element()
+		if p.IsNull() || !p.IsKnown() {
+			continue
+		}
+		name := p.GetAttr("name")
+		if name.IsNull() || !name.IsKnown() {
+			continue
+		}
+		if seen[name.AsString()] {
+			return fmt.Errorf("the cors profile '%s' is defined more than once", name.AsString())
+		}
+		seen[name.AsString()] = true
+	}
+	return nil
+}
+
 var TFAuthenticationProfile = &schema.Resource{
 	Schema: map[string]*schema.Schema{
 		"name":       inOut(_string()),
@@ -85,12 +133,12 @@ var TFOutboundProfile = &schema.Resource{
 		"name":                   required(_string()),
 		"authentication_profile": inOut(_string()),
 		"route_type":             inOut(_string()),
-		"request_policy":         inOut(_string()),
-		"response_policy":        inOut(_string()),
-		"route_policy":           inOut(_string()),
-		"fault_handler_policy":   inOut(_string()),
+		"request_policy":         desc(inOut(_string()), "The key or the name of a 'request' policy"),
+		"response_policy":        desc(inOut(_string()), "The key or the name of a 'response' policy"),
+		"route_policy":           desc(inOut(_string()), "The key or the name of a 'routing' policy"),
+		"fault_handler_policy":   desc(inOut(_string()), "The key or the name of a 'faulthandler' policy"),
 		"api_id":                 inOut(_string()),
-		"api_method_id":          inOut(_string()),
+		"api_method_id":          desc(inOut(_string()), `The id or the name of a method of the backend, e.g. its operationId or "GET /pets/{id}"`),
 		"parameters":             inOut(_list(TFParamValue)),
 	},
 }
@@ -140,7 +188,88 @@ func resourceFrontend() *schema.Resource {
 		ReadContext:   resourceFrontendRead,
 		UpdateContext: resourceFrontendUpdate,
 		DeleteContext: resourceFrontendDelete,
+		CustomizeDiff: customizeFrontend,
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{{
+			Version: 0,
+			Type:    resourceFrontendV0().CoreConfigSchema().ImpliedType(),
+			Upgrade: upgradeRetirementDate,
+		}},
+	}
+}
+
+// The attributes changed without unpublishing the frontend. Any other one requires it,
+// as the API Manager refuses to change them while the frontend is published (or deprecated).
+// The descriptions, summary and image have always been changed live. The others are
+// not sent along the frontend: the state is changed by its own calls (see adaptStates),
+// and so is the upgrade; allow_unpublish is not known to the server, nor are the read-only ones.
+var changedLive = map[string]bool{
+	"description_type":     true,
+	"description_manual":   true,
+	"description_markdown": true,
+	"description_url":      true,
+	"summary":              true,
+	"image_jpg":            true,
+	"image":                true,
+	"image_hash":           true,
+	"image_saved":          true,
+	"state":                true,
+	"upgrade_from":         true,
+	"allow_unpublish":      true,
+	"id":                   true,
+	"created_on":           true,
+	"created_by":           true,
+}
+
+// unpublishNeededFor lists the changed attributes which require to unpublish the frontend.
+func unpublishNeededFor(d interface{ HasChange(string) bool }) (r []string) {
+	for _, k := range TFFrontendSchema.keys() {
+		if !changedLive[k] && d.HasChange(k) {
+			r = append(r, k)
+		}
+	}
+	return r
+}
+
+// without gives the attributes but the given one.
+func without(attrs []string, attr string) (r []string) {
+	for _, a := range attrs {
+		if a != attr {
+			r = append(r, a)
+		}
+	}
+	return r
+}
+
+func customizeFrontend(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if err := checkCorsProfiles(d.GetRawConfig().GetAttr("cors_profile")); err != nil {
+		return err
+	}
+	if err := checkUpgrade(d.GetRawConfig().GetAttr("upgrade_from")); err != nil {
+		return err
+	}
+	if err := customizeImage(ctx, d, m); err != nil {
+		return err
+	}
+	if d.Id() == "" {
+		return nil
+	}
+	if sis, _ := d.GetChange("state"); sis.(string) == unpublished {
+		return nil
+	}
+	attrs := unpublishNeededFor(d)
+	if !d.NewValueKnown("api_id") {
+		// the backend is replaced: unless the replacement moves the frontend itself, so does the update.
+		attrs = append(without(attrs, "api_id"), "api_id")
+	}
+	if len(attrs) > 0 {
+		if d.Get("allow_unpublish") == false {
+			return fmt.Errorf("changing %q requires to unpublish the api %s, which 'allow_unpublish' forbids", attrs, d.Get("name"))
+		}
+		// The SDK offers no way to warn at plan time, so this lands in the logs only.
+		log.Printf("[WARN] changing %q will temporarily unpublish the api %s", attrs, d.Get("name"))
 	}
+	return nil
 }
 
 func resourceFrontendCreate(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
@@ -151,16 +280,49 @@ func resourceFrontendCreate(ctx context.Context, d *schema.ResourceData, m inter
 
 	frontend := &client.Frontend{}
 	expandFrontendForCreate(d, frontend)
+	if err = resolveOutboundProfiles(c, frontend.OutboundProfiles); err != nil {
+		return diag.FromErr(err)
+	}
 
 	err = c.CreateFrontend(frontend)
 	if err != nil {
 		diags = append(diags, diag.FromErr(err)...)
 		return diags
 	}
+	// tracked from now on, should any of the following fail.
+	d.SetId(frontend.Id)
+	// The methods exist only once the frontend is created.
+	if len(d.Get("method").([]interface{})) > 0 {
+		if err = expandFrontendMethods(c, d, frontend); err == nil {
+			err = resolveOutboundProfiles(c, frontend.OutboundProfiles)
+		}
+		if err == nil {
+			err = c.UpdateFrontend(frontend)
+		}
+		if err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+			return diags
+		}
+		diags = append(diags, syncFrontendMethods(c, d, frontend)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+	// The server may not honor the state given on creation.
+	diags = append(diags, adaptStates(c, d.Get("state").(string), retirementDate(d), frontend)...)
+	if diags.HasError() {
+		return diags
+	}
+	diags = append(diags, syncUpgrade(c, d, frontend)...)
+	if diags.HasError() {
+		return diags
+	}
 
-	diags = append(diags, syncImage(d, frontend, c)...)
+	diags = append(diags, syncImage(ctx, m, d, frontend, c)...)
 
+	nameOutboundProfiles(c, frontend.OutboundProfiles, d.Get("outbound_profile"))
 	flattenFrontend(frontend, d)
+	diags = append(diags, flattenFrontendMethods(c, d, frontend)...)
 	return diags
 }
 
@@ -176,9 +338,10 @@ func resourceFrontendRead(ctx context.Context, d *schema.ResourceData, m interfa
 		return diags
 	}
 
-	diags = append(diags, syncImage(d, frontend, c)...)
-
+	nameOutboundProfiles(c, frontend.OutboundProfiles, d.Get("outbound_profile"))
 	flattenFrontend(frontend, d)
+	diags = append(diags, flattenFrontendMethods(c, d, frontend)...)
+	readImage(c, d, frontend)
 
 	return diags
 }
@@ -189,114 +352,137 @@ func resourceFrontendUpdate(ctx context.Context, d *schema.ResourceData, m inter
 		return diag.FromErr(err)
 	}
 
+	frontend, err := c.GetFrontend(d.Id())
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+	// the frontend is already on its new backend when the old one was replaced.
+	server := *frontend
+	server.OutboundProfiles = copyOutboundProfiles(frontend.OutboundProfiles)
+	from, to := d.GetChange("api_id")
+	alreadyMoved := d.HasChange("api_id") && server.ApiId == to
+
 	sis, swant := d.GetChange("state")
 	// we must unpublish if the api is
 	// - not unpublished, AND
-	// - has changes on fields that cannot be forced.
-	mustUnpublish := sis.(string) != unpublished &&
-		d.HasChangesExcept("state",
-			"description_type", "description_manual", "description_markdown", "description_url",
-			"summary",
-			"image_jpg")
-
+	// - has changes on fields that cannot be changed live.
+	attrs := unpublishNeededFor(d)
+	if alreadyMoved {
+		attrs = without(attrs, "api_id")
+	}
+	mustUnpublish := sis.(string) != unpublished && len(attrs) > 0
+	if mustUnpublish && d.Get("allow_unpublish") == false {
+		return diag.Errorf("changing %q requires to unpublish the api %s, which 'allow_unpublish' forbids", attrs, d.Get("name"))
+	}
 	// Unpublish the API, and undeprecate it if needed.
-	var frontend *client.Frontend
 	if mustUnpublish {
-		// A stub object carrying only the id, which is enough for the next few calls
-		frontend = &client.Frontend{Id: d.Id()}
-		s := sis
-		if s == deprecated {
-			// This call also refreshes the frontend object.
-			err := c.UndeprecateFrontend(frontend)
-			if err != nil {
-				diags = append(diags, diag.FromErr(err)...)
-				return diags
-			}
-			s = frontend.State
-		}
-		if s == published {
-			// This call also refreshes the frontend object.
-			err := c.UnpublishFrontend(frontend)
-			if err != nil {
-				diags = append(diags, diag.FromErr(err)...)
-				return diags
-			}
-		}
-		diags = warn(diags, "The api %s has been temporarily unpublished to allow some changes", frontend.Name)
-	} else {
-		frontend, err = c.GetFrontend(d.Id())
-		if err != nil {
-			diags = append(diags, diag.FromErr(err)...)
+		diags = append(diags, adaptStates(c, unpublished, time.Time{}, frontend)...)
+		if diags.HasError() {
 			return diags
 		}
+		diags = warn(diags, "The api %s has been temporarily unpublished to change %q", d.Get("name"), attrs)
 	}
 
 	// Apply the desired changes onto the frontend object
 	if d.HasChangesExcept("state") {
 		expandFrontendForUpdate(d, frontend)
+		if alreadyMoved {
+			keepServerBackend(frontend, &server, from.(string))
+		}
+		if err = expandFrontendMethods(c, d, frontend); err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+			return diags
+		}
+		if err = resolveOutboundProfiles(c, frontend.OutboundProfiles); err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+			return diags
+		}
+		if d.HasChange("api_id") && !alreadyMoved {
+			if err = moveToBackend(c, frontend, from.(string), to.(string)); err != nil {
+				diags = append(diags, diag.FromErr(err)...)
+				return diags
+			}
+		}
 		err = c.UpdateFrontend(frontend)
 		if err != nil {
 			diags = append(diags, diag.FromErr(err)...)
 			return diags
 		}
+		diags = append(diags, syncFrontendMethods(c, d, frontend)...)
+		if diags.HasError() {
+			return diags
+		}
 	}
 
-	diags = append(diags, syncImage(d, frontend, c)...)
-
-	flattenFrontend(frontend, d)
+	diags = append(diags, syncImage(ctx, m, d, frontend, c)...)
 
-	// Fix the state of the proxy
-	diags = append(diags, adaptStates(c, d, swant.(string), frontend)...)
+	// Fix the state of the proxy, as wanted: the frontend is flattened only then.
+	diags = append(diags, adaptStates(c, swant.(string), retirementDate(d), frontend)...)
 	if diags.HasError() {
 		return diags
 	}
+	diags = append(diags, syncUpgrade(c, d, frontend)...)
+	if diags.HasError() {
+		return diags
+	}
+	nameOutboundProfiles(c, frontend.OutboundProfiles, d.Get("outbound_profile"))
 	flattenFrontend(frontend, d)
+	diags = append(diags, flattenFrontendMethods(c, d, frontend)...)
 	d.Set("last_updated", time.Now().Format(time.RFC850))
 
 	return diags
 }
 
+// keepServerBackend takes the profiles still on the backend 'from' as found on the server,
+// where the frontend has been moved to a new backend already (see replaceBackend).
+func keepServerBackend(frontend, server *client.Frontend, from string) {
+	for k, p := range frontend.OutboundProfiles {
+		if s, ok := server.OutboundProfiles[k]; ok && p.ApiId == from {
+			frontend.OutboundProfiles[k] = s
+		}
+	}
+	for k, p := range frontend.ServiceProfiles {
+		if s, ok := server.ServiceProfiles[k]; ok && p.ApiId == from {
+			frontend.ServiceProfiles[k] = s
+		}
+	}
+}
+
+func copyOutboundProfiles(profiles map[string]client.OutboundProfile) map[string]client.OutboundProfile {
+	r := make(map[string]client.OutboundProfile, len(profiles))
+	for k, v := range profiles {
+		r[k] = v
+	}
+	return r
+}
+
+// listFrontends lists all the frontends. The client fails to do it, as it
+// gives no pointer to read them into.
+func listFrontends(c *client.Client) (ret []client.Frontend, err error) {
+	err = restGet(c, &ret, "proxies")
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// moveToBackend points the profiles of the frontend to the backend 'to' instead of 'from'.
+func moveToBackend(c *client.Client, frontend *client.Frontend, from, to string) error {
+	remap, err := backendMethodRemap(c, from, to, frontend)
+	if err != nil {
+		return err
+	}
+	repointFrontend(frontend, from, to, remap)
+	return nil
+}
+
 const (
 	published   = "published"
 	unpublished = "unpublished"
 	deprecated  = "deprecated"
 )
 
-func adaptStates(c *client.Client, d *schema.ResourceData, state string, frontend *client.Frontend) (diags diag.Diagnostics) {
-	d0 := frontend.Deprecated
-	s0 := frontend.State
-	s1 := state
-	if d0 && s0 == published {
-		s0 = deprecated
-	}
-	if !diags.HasError() {
-		transition := fmt.Sprintf("%s -> %s", s0, s1)
-		switch transition {
-		case unpublished + " -> " + published:
-			diags = guard(diags, c.PublishFrontend, frontend)
-		case unpublished + " -> " + deprecated:
-			diags = guard(diags, c.PublishFrontend, frontend)
-			diags = guard(diags, c.DeprecateFrontend, frontend)
-		case published + " -> " + unpublished:
-			diags = guard(diags, c.UnpublishFrontend, frontend)
-		case published + " -> " + deprecated:
-			diags = guard(diags, c.DeprecateFrontend, frontend)
-		case deprecated + " -> " + published:
-			diags = guard(diags, c.UndeprecateFrontend, frontend)
-		case deprecated + " -> " + unpublished:
-			diags = guard(diags, c.UndeprecateFrontend, frontend)
-			diags = guard(diags, c.UnpublishFrontend, frontend)
-		case deprecated + " -> " + deprecated,
-			published + " -> " + published,
-			unpublished + " -> " + unpublished:
-			// nothing to do, the api is already in the expected state.
-		default:
-			// could be e.g. anything starting from pending
-			diags = warn(diags, "transition (%s) is not implemented: ignoring this change", transition)
-		}
-	}
-	return diags
-}
 func resourceFrontendDelete(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
 	c, err := m.(*ProviderState).GetClient()
 	if err != nil {
@@ -320,6 +506,10 @@ func resourceFrontendDelete(ctx context.Context, d *schema.ResourceData, m inter
 }
 
 func flattenFrontend(c *client.Frontend, d *schema.ResourceData) {
+	// the profiles of the methods are in the method blocks
+	methods := frontendMethodIds(d.Get("method"))
+	inbound := inboundProfilesExcept(c.InboundProfiles, methods)
+	outbound := outboundProfilesExcept(c.OutboundProfiles, methods)
 	d.SetId(c.Id)
 	d.Set("org_id", c.OrganizationId)                    //inOut(_string())
 	d.Set("api_id", c.ApiId)                             //inOut(_string())
@@ -335,17 +525,14 @@ func flattenFrontend(c *client.Frontend, d *schema.ResourceData) {
 	d.Set("summary", c.Summary)                          //inOut(_string())
 	d.Set("retired", c.Retired)                          //inOut(_bool())
 	d.Set("expired", c.Expired)                          //inOut(_bool())
-	d.Set("retirement_date", c.RetirementDate)           //inOut(_int())
-	if c.Deprecated {
-		d.Set("state", deprecated)
-	} else {
-		d.Set("state", c.State)
-	}
+	d.Set("state", frontendState(c, d.Get("state").(string), retirementDate(d)))
+	// kept in the same form as configured when it is the same date
+	d.Set("retirement_date", flattenRetirementDate(c.RetirementDate, d.Get("retirement_date")))
 	d.Set("cors_profile", flattenCorsProfiles(c.CorsProfiles))                               //inOut(_list(TFCorsProfile))
 	d.Set("security_profile", flattenSecurityProfiles(c.SecurityProfiles))                   //inOut(_list(TFSecurityProfile))
 	d.Set("authentication_profile", flattenAuthenticationProfiles(c.AuthenticationProfiles)) //inOut(_list(TFAuthenticationProfile))
-	d.Set("inbound_profile", flattenInboundProfiles(c.InboundProfiles))                      //inOut(_namedMap(TFInboundProfile))
-	d.Set("outbound_profile", flattenOutboundProfiles(c.OutboundProfiles))                   //inOut(_namedMap(TFOutboundProfile))
+	d.Set("inbound_profile", flattenInboundProfiles(inbound))                                //inOut(_namedMap(TFInboundProfile))
+	d.Set("outbound_profile", flattenOutboundProfiles(outbound))                             //inOut(_namedMap(TFOutboundProfile))
 	d.Set("service_profile", flattenServiceProfiles(c.ServiceProfiles))                      //inOut(_namedMap(TFServiceProfile))
 	d.Set("ca_cert", flattenCACerts(c.CACerts))                                              //inOut(_list(TFCACert))
 	d.Set("tag", flattenTags(c.Tags))                                                        //inOut(_pnamedMap(_plist(schema.TypeString)))
@@ -360,9 +547,9 @@ func flattenCorsProfiles(c []client.CorsProfile) []flattenMap {
 		r[i] = flattenMap{
 			"name":                a.Name,               //required(_string())
 			"is_default":          a.IsDefault,          //required(_bool())
-			"origins":             a.Origins,            //required(_plist(schema.TypeString))
-			"allowed_headers":     a.AllowedHeaders,     //required(_plist(schema.TypeString))
-			"exposed_headers":     a.ExposedHeaders,     //required(_plist(schema.TypeString))
+			"origins":             a.Origins,            //required(_psetOf(_string(validOrigin)))
+			"allowed_headers":     a.AllowedHeaders,     //required(_psetOf(_string(validHeaderName)))
+			"exposed_headers":     a.ExposedHeaders,     //required(_psetOf(_string(validHeaderName)))
 			"support_credentials": a.SupportCredentials, //required(_bool())
 			"max_age_seconds":     a.MaxAgeSeconds,      //inOut(_int())
 		}
@@ -489,21 +676,21 @@ func expandFrontendForCreate(d *schema.ResourceData, frontend *client.Frontend)
 }
 func expandFrontendForUpdate(d *schema.ResourceData, frontend *client.Frontend) {
 	frontend.Id = d.Id()
-	frontend.OrganizationId = d.Get("org_id").(string)                    //inOut(_string())
-	frontend.ApiId = d.Get("api_id").(string)                             //inOut(_string())
-	frontend.Name = d.Get("name").(string)                                //inOut(_string())
-	frontend.Version = d.Get("version").(string)                          //inOut(_string())
-	frontend.ApiRoutingKey = d.Get("api_routing_key").(string)            //inOut(_string())
-	frontend.Vhost = d.Get("vhost").(string)                              //inOut(_string())
-	frontend.Path = d.Get("path").(string)                                //inOut(_string())
-	frontend.DescriptionType = d.Get("description_type").(string)         //inOut(_string())
-	frontend.DescriptionManual = d.Get("description_manual").(string)     //inOut(_string())
-	frontend.DescriptionMarkdown = d.Get("description_markdown").(string) //inOut(_string())
-	frontend.DescriptionUrl = d.Get("description_url").(string)           //inOut(_string())
-	frontend.Summary = d.Get("summary").(string)                          //inOut(_string())
-	frontend.Retired = d.Get("retired").(bool)                            //inOut(_bool())
-	frontend.Expired = d.Get("expired").(bool)                            //inOut(_bool())
-	frontend.RetirementDate = d.Get("retirement_date").(int)              //inOut(_int())
+	frontend.OrganizationId = d.Get("org_id").(string)                                //inOut(_string())
+	frontend.ApiId = d.Get("api_id").(string)                                         //inOut(_string())
+	frontend.Name = d.Get("name").(string)                                            //inOut(_string())
+	frontend.Version = d.Get("version").(string)                                      //inOut(_string())
+	frontend.ApiRoutingKey = d.Get("api_routing_key").(string)                        //inOut(_string())
+	frontend.Vhost = d.Get("vhost").(string)                                          //inOut(_string())
+	frontend.Path = d.Get("path").(string)                                            //inOut(_string())
+	frontend.DescriptionType = d.Get("description_type").(string)                     //inOut(_string())
+	frontend.DescriptionManual = d.Get("description_manual").(string)                 //inOut(_string())
+	frontend.DescriptionMarkdown = d.Get("description_markdown").(string)             //inOut(_string())
+	frontend.DescriptionUrl = d.Get("description_url").(string)                       //inOut(_string())
+	frontend.Summary = d.Get("summary").(string)                                      //inOut(_string())
+	frontend.Retired = d.Get("retired").(bool)                                        //inOut(_bool())
+	frontend.Expired = d.Get("expired").(bool)                                        //inOut(_bool())
+	frontend.RetirementDate = expandRetirementDate(d.Get("retirement_date").(string)) //inOut(_string(validRFC3339))
 	if v, ok := d.GetOk("cors_profile"); ok {
 		frontend.CorsProfiles = expandCorsProfiles(v)
 	}
@@ -540,9 +727,9 @@ func expandCorsProfiles(v interface{}) []client.CorsProfile {
 		a := b.(map[string]interface{})
 		r[i].Name = a["name"].(string)                            //required(_string())
 		r[i].IsDefault = a["is_default"].(bool)                   //required(_bool())
-		r[i].Origins = toStringArray(a["origins"])                //required(_plist(schema.TypeString))
-		r[i].AllowedHeaders = toStringArray(a["allowed_headers"]) //required(_plist(schema.TypeString))
-		r[i].ExposedHeaders = toStringArray(a["exposed_headers"]) //required(_plist(schema.TypeString))
+		r[i].Origins = toStringArray(a["origins"])                //required(_psetOf(_string(validOrigin)))
+		r[i].AllowedHeaders = toStringArray(a["allowed_headers"]) //required(_psetOf(_string(validHeaderName)))
+		r[i].ExposedHeaders = toStringArray(a["exposed_headers"]) //required(_psetOf(_string(validHeaderName)))
 		r[i].SupportCredentials = a["support_credentials"].(bool) //required(_bool())
 		r[i].MaxAgeSeconds = a["max_age_seconds"].(int)           //inOut(_int())
 	}
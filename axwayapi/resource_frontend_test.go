@@ -0,0 +1,38 @@
+package axwayapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+type changes map[string]bool
+
+func (c changes) HasChange(k string) bool { return c[k] }
+
+func TestChangedLive(t *testing.T) {
+	for k := range changedLive {
+		if _, ok := (*TFFrontendSchema)[k]; !ok {
+			t.Errorf("%s is not an attribute of the frontends", k)
+		}
+	}
+}
+
+func TestUnpublishNeededFor(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		changed changes
+		want    []string
+	}{
+		{"nothing", changes{}, nil},
+		{"descriptions", changes{"summary": true, "description_manual": true, "image": true, "state": true}, nil},
+		// only the attributes known to be changed live are.
+		{"tags", changes{"tag": true, "summary": true}, []string{"tag"}},
+		{"sorted", changes{"retirement_date": true, "custom_properties": true, "api_id": true}, []string{"api_id", "custom_properties", "retirement_date"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unpublishNeededFor(tt.changed); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -13,7 +13,10 @@ var TFOrgSchema = schemaMap{
 	"name":             required(_string()),
 	"description":      inOut(_string()),
 	"email":            inOut(_string()),
-	"image_jpg":        inOut(_hashedString()),
+	"image_jpg":        _imageJpg(inOut(_hashedString())),
+	"image":            _image(),
+	"image_hash":       _imageHash(),
+	"image_saved":      _imageSaved(),
 	"restricted":       readonly(_bool()),
 	"virtual_host":     inOut(_string()),
 	"phone":            inOut(_string()),
@@ -34,6 +37,7 @@ func resourceOrganization() *schema.Resource {
 		ReadContext:   resourceOrgRead,
 		UpdateContext: resourceOrgUpdate,
 		DeleteContext: resourceOrgDelete,
+		CustomizeDiff: customizeImage,
 	}
 }
 
@@ -50,7 +54,7 @@ func resourceOrgCreate(ctx context.Context, d *schema.ResourceData, m interface{
 		diags = append(diags, diag.FromErr(err)...)
 		return diags
 	}
-	diags = append(diags, syncImage(d, org, c)...)
+	diags = append(diags, syncImage(ctx, m, d, org, c)...)
 
 	flattenOrg(org, d)
 
@@ -70,6 +74,7 @@ func resourceOrgRead(ctx context.Context, d *schema.ResourceData, m interface{})
 	}
 
 	flattenOrg(org, d)
+	readImage(c, d, org)
 
 	return diags
 }
@@ -88,7 +93,7 @@ func resourceOrgUpdate(ctx context.Context, d *schema.ResourceData, m interface{
 		return diags
 	}
 
-	diags = append(diags, syncImage(d, org, c)...)
+	diags = append(diags, syncImage(ctx, m, d, org, c)...)
 
 	flattenOrg(org, d)
 
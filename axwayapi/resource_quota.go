@@ -1,20 +1,22 @@
 package axwayapi
 
 import (
+	"context"
 	"fmt"
 	"hash/fnv"
-	"regexp"
+	"math/big"
 	"strconv"
+	"strings"
+	"unicode"
 
 	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-const (
-	limit_pattern = `^\s*(?P<nb>\d+) *(?P<unit>(MB)|(msg)) *((per)|(\/)) *(?P<t>\d*) *(?P<tunit>(se?c?o?n?d?)|(mi?n?u?t?e?)|(ho?u?r?)|(da?y?)|(we?e?k?))s?$`
-)
-
-var rLimit = regexp.MustCompile(limit_pattern)
+// the periods understood by the server, in the canonical form.
+var timeUnits = []string{"second", "minute", "hour", "day", "week", "month"}
 
 var TFQuotaSchema = schemaMap{
 	"name":        optional(_string()),
@@ -26,26 +28,56 @@ var TFQuotaSchema = schemaMap{
 var TFRestriction = &schema.Resource{
 	Schema: map[string]*schema.Schema{
 		"api_id": required(_string()),
-		"method": desc(optional(_string(), "*"), `the name of the method to limit, or '*' (the default) to limit them all`),
-		"limit": desc(required(_apply(compressRestriction, _string(r(limit_pattern)))),
+		"method": desc(optional(_string(), "*"),
+			`the method to limit, or '*' (the default) to limit them all.
+			A method is designated by its name (usually the operationId in the spec) or by "<VERB> <path>", e.g. "GET /pets/{id}".`),
+		"limit": desc(optional(_apply(compressRestriction, _string(validLimit))),
 			`A string in the form :
 			  - <nb_msg> msg per <t> <tunit>
-			  - <nb_mb> MB per <t> <tunit>
-			 In both cases, <t> is a positive int, <tunit> is one of second, minute, hour, day, week (with an optional trailing 's')
+			  - <size> <sunit> per <t> <tunit>
+			 <t> is a positive int, <tunit> is one of second, minute, hour, day, week, month (with an optional trailing 's').
+			 Units can be abbreviated as long as there is no ambiguity: 'mo' is a month, 'mi' or 'm' a minute.
 			 If <t> is missing, a value of 1 is implied
-			 The first form places a limit in the nb od messages per interval.
-			 Conversely, the second form places a limit in mega bytes in the interval.
+			 The first form places a limit in the nb of messages per interval.
+			 Conversely, the second form places a limit in volume in the interval: <sunit> is one of KB, MB or GB,
+			 and <size> may be decimal. As the server counts in whole MB, a size that is not one is scaled
+			 together with its interval, e.g. 1.5MB per minute is 3MB per 2 minutes, and 512KB per second is 1MB per 2 seconds.
+			 Beware that a scaled limit allows larger bursts: 1MB may go through at once in the 2 seconds.
 			 Example:
 			 	- 20 MB per minute
 				- 100 msg per 2 minutes
+				- 1.5GB per month
 			The syntax is pretty lax, so "10MB/s", "10msg per 5secs", "10 msg / 5 sec" all work.
+			Exactly one of 'limit' and 'throttle' must be given.
 			`),
+		"throttle": desc(optional(_listMax(1, TFThrottle)),
+			`The structured alternative to 'limit', e.g. throttle { messages = 100, per = 2, period = "minute" }`),
 	},
 }
 
+// The structured form of a limit. It cannot be named 'limit' like in
+// "limit { messages = 100, per = 2, period = "minute" }", as 'limit' is the string form.
+var TFThrottle = resource(schemaMap{
+	"messages": desc(optional(_int()), "The nb of messages allowed in the period. Exclusive with 'mb'"),
+	"mb":       desc(optional(_int()), "The nb of mega bytes allowed in the period. Exclusive with 'messages'"),
+	"per":      desc(optional(_int(), 1), "The nb of periods making the interval, 1 by default"),
+	"period":   desc(required(_string(oneOf(timeUnits...))), "One of second, minute, hour, day, week, month"),
+})
+
+func validLimit(value interface{}, path cty.Path) diag.Diagnostics {
+	if _, err := RestrictionFromString(value.(string)); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
 func compressRestriction(s interface{}) string {
-	r := RestrictionFromString(s.(string))
-	return fmt.Sprintf("%d%s/%d%s", r.nb, r.unit, r.time, canonTUnit(r.tunit))
+	r, err := RestrictionFromString(s.(string))
+	if err != nil {
+		// rejected by the validation anyway.
+		return s.(string)
+	}
+	return r.String()
 }
 
 type restriction struct {
@@ -53,39 +85,127 @@ type restriction struct {
 	unit, tunit string
 }
 
-func RestrictionFromString(s string) *restriction {
-	matches := rLimit.FindStringSubmatch(s)
-	r := &restriction{time: 1}
-	nb, err := strconv.Atoi(matches[rLimit.SubexpIndex("nb")])
+func (r *restriction) String() string {
+	return fmt.Sprintf("%d%s/%d%s", r.nb, r.unit, r.time, r.tunit)
+}
+
+// tokenizeLimit splits a limit into numbers, words and slashes, ignoring blanks.
+func tokenizeLimit(s string) ([]string, error) {
+	tokens := []string{}
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		start := i
+		switch r := runes[i]; {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '/':
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+		case unicode.IsLetter(r):
+			for i < len(runes) && unicode.IsLetter(runes[i]) {
+				i++
+			}
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' in limit '%s'", r, s)
+		}
+		tokens = append(tokens, string(runes[start:i]))
+	}
+	return tokens, nil
+}
+
+// The largest factor an interval is scaled by, to count a size in whole MB: 1KB is 1MB per 1024 intervals.
+const maxLimitScale = 1024
+
+func RestrictionFromString(s string) (*restriction, error) {
+	tokens, err := tokenizeLimit(s)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	syntax := fmt.Errorf("cannot understand the limit '%s', expecting '<nb> <unit> per [<t>] <tunit>'", s)
+	next := func() string {
+		if len(tokens) == 0 {
+			return ""
+		}
+		t := tokens[0]
+		tokens = tokens[1:]
+		return t
 	}
-	r.nb = nb
 
-	var t int
-	_t := matches[rLimit.SubexpIndex("t")]
-	if _t == "" {
-		t = 1
-	} else {
-		t, err = strconv.Atoi(_t)
-		if err != nil {
-			panic(err)
+	// decimals are kept exact, as rationals.
+	nb, ok := new(big.Rat).SetString(next())
+	if !ok || nb.Sign() < 0 {
+		return nil, syntax
+	}
+	r := &restriction{time: 1}
+	// the interval is multiplied by scale, when the size is not a whole number of MB.
+	scale := 1
+	switch unit := strings.ToLower(next()); unit {
+	case "msg", "msgs", "message", "messages":
+		if !nb.IsInt() {
+			return nil, fmt.Errorf("the nb of messages must be a whole number in limit '%s'", s)
+		}
+		r.unit, r.nb = "msg", int(nb.Num().Int64())
+	case "kb", "mb", "gb":
+		mb := nb.Mul(nb, map[string]*big.Rat{"kb": big.NewRat(1, 1024), "mb": big.NewRat(1, 1), "gb": big.NewRat(1024, 1)}[unit])
+		if !mb.Denom().IsInt64() || mb.Denom().Int64() > maxLimitScale {
+			return nil, fmt.Errorf("the size in limit '%s' is too precise, it cannot be counted in whole MB", s)
+		}
+		scale = int(mb.Denom().Int64())
+		r.unit, r.nb = "MB", int(mb.Num().Int64())
+	default:
+		return nil, syntax
+	}
+	if sep := strings.ToLower(next()); sep != "per" && sep != "/" {
+		return nil, syntax
+	}
+	t := next()
+	if len(tokens) > 0 {
+		r.time, err = strconv.Atoi(t)
+		if err != nil || r.time <= 0 {
+			return nil, fmt.Errorf("the interval must be a positive whole number in limit '%s'", s)
 		}
+		t = next()
 	}
-	r.time = t
-	r.unit = matches[rLimit.SubexpIndex("unit")]
-	r.tunit = matches[rLimit.SubexpIndex("tunit")]
-	return r
+	if r.tunit, err = canonTUnitOf(t); err != nil {
+		return nil, fmt.Errorf("%v in limit '%s'", err, s)
+	}
+	if len(tokens) > 0 {
+		return nil, syntax
+	}
+	r.time *= scale
+	return r, nil
 }
 
-func flattenRestriction(restriction []client.Constraint) *schema.Set { //[]flattenMap {
+func flattenRestriction(restriction []client.Constraint, prior interface{}) *schema.Set { //[]flattenMap {
+	// Restrictions are rendered the way they are written in the prior state,
+	// either as a 'limit' string or a 'throttle' block.
+	structured := map[string]bool{}
+	if set, ok := prior.(*schema.Set); ok {
+		for _, p := range set.List() {
+			a := p.(map[string]interface{})
+			if t, ok := a["throttle"].([]interface{}); ok && len(t) > 0 {
+				structured[fmt.Sprintf("%s/%s", a["api_id"], a["method"])] = true
+			}
+		}
+	}
 	ret := make([]interface{}, 0)
 	for _, r := range restriction {
-		ret = append(ret, flattenMap{
-			"api_id": r.Api,
-			"method": r.Method,
-			"limit":  flattenRestrictionConfig(r.Config),
-		})
+		f := flattenMap{
+			"api_id":   r.Api,
+			"method":   r.Method,
+			"limit":    "",
+			"throttle": []interface{}{},
+		}
+		if structured[r.Api+"/"+r.Method] {
+			f["throttle"] = []interface{}{flattenThrottle(r.Config)}
+		} else {
+			f["limit"] = flattenRestrictionConfig(r.Config)
+		}
+		ret = append(ret, f)
 	}
 
 	return schema.NewSet(func(i interface{}) int {
@@ -104,6 +224,16 @@ func flattenRestrictionConfig(config interface{}) string {
 		panic(fmt.Errorf("cannot parse config (unknown type %T): %#+v", config, config))
 	}
 }
+func flattenThrottle(config interface{}) flattenMap {
+	switch c := config.(type) {
+	case client.ConstraintConfigMb:
+		return flattenMap{"mb": c.Mb, "messages": 0, "per": c.Per, "period": canonTUnit(c.Period)}
+	case client.ConstraintConfigMsg:
+		return flattenMap{"mb": 0, "messages": c.Msg, "per": c.Per, "period": canonTUnit(c.Period)}
+	default:
+		panic(fmt.Errorf("cannot parse config (unknown type %T): %#+v", config, config))
+	}
+}
 func expandRestrictions(v interface{}) (quota []client.Constraint) {
 	c := v.(*schema.Set).List()
 	r := make([]client.Constraint, 0)
@@ -112,7 +242,7 @@ func expandRestrictions(v interface{}) (quota []client.Constraint) {
 		if a["api_id"] != "" {
 			// Unfortunate test but this seems to be necessary
 			// to avoid phantom items in the set
-			c := expandRestrictionConfig(a["limit"])
+			c := expandRestrictionConfig(a)
 			var cc client.Constraint
 			switch c.(type) {
 			case client.ConstraintConfigMb:
@@ -132,45 +262,201 @@ func expandRestrictions(v interface{}) (quota []client.Constraint) {
 	return r
 }
 
-func expandRestrictionConfig(v interface{}) (config interface{}) {
-	matches := rLimit.FindStringSubmatch(v.(string))
-	if len(matches) == 0 {
-		panic(fmt.Errorf("cannot understand the restriction.limit string: '%+#v'", v))
+func expandRestrictionConfig(a map[string]interface{}) (config interface{}) {
+	if t, ok := a["throttle"].([]interface{}); ok && len(t) > 0 && t[0] != nil {
+		th := t[0].(map[string]interface{})
+		per, period := th["per"].(int), th["period"].(string)
+		if mb := th["mb"].(int); mb > 0 {
+			return client.ConstraintConfigMb{Mb: mb, Per: per, Period: period}
+		}
+		return client.ConstraintConfigMsg{Msg: th["messages"].(int), Per: per, Period: period}
 	}
-	nb, err := strconv.Atoi(matches[rLimit.SubexpIndex("nb")])
+	r, err := RestrictionFromString(a["limit"].(string))
 	if err != nil {
-		panic(err)
+		panic(fmt.Errorf("cannot understand the restriction.limit string: %v", err))
+	}
+	switch r.unit {
+	case "MB":
+		return client.ConstraintConfigMb{Mb: r.nb, Per: r.time, Period: r.tunit}
+	default:
+		return client.ConstraintConfigMsg{Msg: r.nb, Per: r.time, Period: r.tunit}
+	}
+}
+
+// canonTUnitOf gives the canonical form of a time unit.
+// Any prefix of a unit, with an optional trailing 's', designates this unit,
+// as long as it does not designate another one too ("m" has always meant minute,
+// so it is kept as such; "mo" is a month).
+func canonTUnitOf(tunit string) (string, error) {
+	w := strings.ToLower(tunit)
+	candidates := []string{w}
+	if len(w) > 1 && strings.HasSuffix(w, "s") {
+		candidates = append(candidates, strings.TrimSuffix(w, "s"))
 	}
-	t, err := strconv.Atoi(matches[rLimit.SubexpIndex("t")])
+	for _, c := range candidates {
+		// "ms" is not minutes.
+		if c == "m" && c == w {
+			return "minute", nil
+		}
+		found := []string{}
+		for _, u := range timeUnits {
+			if c != "" && strings.HasPrefix(u, c) {
+				found = append(found, u)
+			}
+		}
+		switch len(found) {
+		case 1:
+			return found[0], nil
+		case 0:
+			continue
+		default:
+			return "", fmt.Errorf("ambiguous time unit '%s' (%s?)", tunit, strings.Join(found, " or "))
+		}
+	}
+	return "", fmt.Errorf("unknown time unit '%s'", tunit)
+}
+
+func canonTUnit(tunit string) string {
+	norm, err := canonTUnitOf(tunit)
 	if err != nil {
 		panic(err)
 	}
-	unit := matches[rLimit.SubexpIndex("unit")]
-	tunit := canonTUnit(matches[rLimit.SubexpIndex("tunit")])
-	switch unit {
-	case "MB":
-		return client.ConstraintConfigMb{Mb: nb, Per: t, Period: tunit}
-	case "msg":
-		return client.ConstraintConfigMsg{Msg: nb, Per: t, Period: tunit}
+	return norm
+}
+
+// resolveRestrictionMethods replaces the method names in the restrictions
+// by the method ids that the server expects.
+func resolveRestrictionMethods(c *client.Client, restrictions []client.Constraint) error {
+	idxs := methodIndexes{}
+	for i, r := range restrictions {
+		if r.Method == "*" {
+			continue
+		}
+		idx, err := idxs.get(c, r.Api)
+		if err != nil {
+			return fmt.Errorf("cannot resolve method '%s' of api %s: %w", r.Method, r.Api, err)
+		}
+		id, err := idx.resolve(r.Method)
+		if err != nil {
+			return err
+		}
+		restrictions[i].Method = id
 	}
-	return r
+	return nil
 }
 
-func canonTUnit(tunit string) string {
-	units := make(map[string]*regexp.Regexp, 5)
-	units["second"] = regexp.MustCompile(`^se?c?o?n?d?s?$`)
-	units["minute"] = regexp.MustCompile(`^mi?n?u?t?e?s?$`)
-	units["hour"] = regexp.MustCompile(`^ho?u?r?s?$`)
-	units["day"] = regexp.MustCompile(`^da?y?s?$`)
-	units["week"] = regexp.MustCompile(`^we?e?k?s?$`)
-
-	// Works reliably only bc patterns do not overlap.
-	// If for instance, "month" is added, it would conflict with "minutes"
-	// since both would match a single 'm'.
-	for norm, pattern := range units {
-		if pattern.MatchString(tunit) {
-			return norm
-		}
-	}
-	panic(fmt.Errorf("unnknown time unit '%s'", tunit))
+// nameRestrictionMethods replaces the method ids in the restrictions by their names.
+// When a prior restriction designates the same method, its wording is kept to avoid
+// spurious diffs (e.g. "GET /pets" vs "listPets").
+func nameRestrictionMethods(c *client.Client, restrictions []client.Constraint, prior interface{}) {
+	idxs := methodIndexes{}
+	known := map[string]string{}
+	if set, ok := prior.(*schema.Set); ok {
+		for _, p := range set.List() {
+			a := p.(map[string]interface{})
+			api, method := a["api_id"].(string), a["method"].(string)
+			if api == "" || method == "*" {
+				continue
+			}
+			if idx, err := idxs.get(c, api); err == nil {
+				if id, err := idx.resolve(method); err == nil {
+					known[api+"/"+id] = method
+				}
+			}
+		}
+	}
+	for i, r := range restrictions {
+		if r.Method == "*" {
+			continue
+		}
+		if name, ok := known[r.Api+"/"+r.Method]; ok {
+			restrictions[i].Method = name
+		} else if idx, err := idxs.get(c, r.Api); err == nil {
+			restrictions[i].Method = idx.nameOf(r.Method)
+		}
+	}
+}
+
+func checkRestrictionLimit(r cty.Value) error {
+	limit, throttle := r.GetAttr("limit"), r.GetAttr("throttle")
+	if !limit.IsKnown() || !throttle.IsKnown() {
+		return nil
+	}
+	hasLimit := !limit.IsNull()
+	hasThrottle := !throttle.IsNull() && throttle.LengthInt() > 0
+	if hasLimit == hasThrottle {
+		return fmt.Errorf("exactly one of 'limit' and 'throttle' must be given for a restriction")
+	}
+	if hasThrottle {
+		t := throttle.Index(cty.NumberIntVal(0))
+		msg, mb := t.GetAttr("messages"), t.GetAttr("mb")
+		if msg.IsKnown() && mb.IsKnown() && msg.IsNull() == mb.IsNull() {
+			return fmt.Errorf("exactly one of 'messages' and 'mb' must be given in a throttle block")
+		}
+	}
+	return nil
+}
+
+// customizeRestrictions checks at plan time the restrictions found in the given blocks.
+func customizeRestrictions(blocks ...string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		raw := d.GetRawConfig()
+		if raw.IsNull() || !raw.IsKnown() {
+			return nil
+		}
+		restrictions := []cty.Value{}
+		for _, block := range blocks {
+			if !raw.Type().HasAttribute(block) {
+				continue
+			}
+			b := raw.GetAttr(block)
+			if b.IsNull() || !b.IsKnown() || b.LengthInt() == 0 {
+				continue
+			}
+			rs := b.Index(cty.NumberIntVal(0)).GetAttr("restriction")
+			if rs.IsNull() || !rs.IsKnown() {
+				continue
+			}
+			for it := rs.ElementIterator(); it.Next(); {
+				_, r := it.Element()
+				restrictions = append(restrictions, r)
+			}
+		}
+		return checkRestrictions(m, restrictions)
+	}
+}
+
+// checkRestrictions checks that each restriction has exactly one of 'limit' and 'throttle',
+// and that their methods exist. Methods of apis not known yet are not checked.
+func checkRestrictions(m interface{}, restrictions []cty.Value) error {
+	type apiMethod struct{ api, method string }
+	toCheck := []apiMethod{}
+	for _, r := range restrictions {
+		if err := checkRestrictionLimit(r); err != nil {
+			return err
+		}
+		api, method := r.GetAttr("api_id"), r.GetAttr("method")
+		if api.IsNull() || !api.IsKnown() || method.IsNull() || !method.IsKnown() || method.AsString() == "*" {
+			continue
+		}
+		toCheck = append(toCheck, apiMethod{api.AsString(), method.AsString()})
+	}
+	if len(toCheck) == 0 {
+		return nil
+	}
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return err
+	}
+	idxs := methodIndexes{}
+	for _, am := range toCheck {
+		idx, err := idxs.get(c, am.api)
+		if err != nil {
+			return fmt.Errorf("cannot check method '%s' of api %s: %v", am.method, am.api, err)
+		}
+		if _, err := idx.resolve(am.method); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,92 @@
+package axwayapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRestrictionFromString(t *testing.T) {
+	for _, tt := range []struct {
+		limit string
+		want  *restriction
+	}{
+		{"20 MB per minute", &restriction{nb: 20, unit: "MB", time: 1, tunit: "minute"}},
+		{"100 msg per 2 minutes", &restriction{nb: 100, unit: "msg", time: 2, tunit: "minute"}},
+		{"10MB/s", &restriction{nb: 10, unit: "MB", time: 1, tunit: "second"}},
+		{"10msg per 5secs", &restriction{nb: 10, unit: "msg", time: 5, tunit: "second"}},
+		{"10 msg / 5 sec", &restriction{nb: 10, unit: "msg", time: 5, tunit: "second"}},
+		{"3 messages per mo", &restriction{nb: 3, unit: "msg", time: 1, tunit: "month"}},
+		{"1 msg per m", &restriction{nb: 1, unit: "msg", time: 1, tunit: "minute"}},
+		{"0 msg per day", &restriction{nb: 0, unit: "msg", time: 1, tunit: "day"}},
+		// sizes are counted in whole MB, scaling the interval when needed.
+		{"1.5GB per month", &restriction{nb: 1536, unit: "MB", time: 1, tunit: "month"}},
+		{"1.5MB per minute", &restriction{nb: 3, unit: "MB", time: 2, tunit: "minute"}},
+		{"512KB per second", &restriction{nb: 1, unit: "MB", time: 2, tunit: "second"}},
+		{"1KB per 3 hours", &restriction{nb: 1, unit: "MB", time: 3072, tunit: "hour"}},
+		{"2048 kb / week", &restriction{nb: 2, unit: "MB", time: 1, tunit: "week"}},
+	} {
+		t.Run(tt.limit, func(t *testing.T) {
+			got, err := RestrictionFromString(tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestrictionFromStringErrors(t *testing.T) {
+	for _, limit := range []string{
+		"",
+		"MB per minute",
+		"-1 msg per minute",
+		"1.5 msg per minute",
+		"10 requests per minute",
+		"10 msg each minute",
+		"10 msg per",
+		"10 msg per 0 minute",
+		"10 msg per 1.5 minute",
+		"10 msg per fortnight",
+		// second or seconds?
+		"10 msg per s minute",
+		"10 msg per 2 minutes more",
+		"10 msg per minute!",
+		// 1/2048 MB cannot be counted with an interval scaled up to 1024 times.
+		"0.5KB per second",
+	} {
+		t.Run(limit, func(t *testing.T) {
+			if r, err := RestrictionFromString(limit); err == nil {
+				t.Errorf("got %+v, want an error", r)
+			}
+		})
+	}
+}
+
+func TestCanonTUnitOf(t *testing.T) {
+	for _, tt := range []struct {
+		tunit, want string
+	}{
+		{"second", "second"},
+		{"s", "second"},
+		{"secs", "second"},
+		{"m", "minute"},
+		{"mi", "minute"},
+		{"mins", "minute"},
+		{"mo", "month"},
+		{"Months", "month"},
+		{"h", "hour"},
+		{"d", "day"},
+		{"w", "week"},
+	} {
+		if got, err := canonTUnitOf(tt.tunit); err != nil || got != tt.want {
+			t.Errorf("%s: got %q, %v, want %q", tt.tunit, got, err, tt.want)
+		}
+	}
+	for _, tunit := range []string{"", "ms", "x", "years"} {
+		if got, err := canonTUnitOf(tunit); err == nil {
+			t.Errorf("%s: got %q, want an error", tunit, got)
+		}
+	}
+}
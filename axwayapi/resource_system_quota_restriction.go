@@ -0,0 +1,298 @@
+package axwayapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// A single restriction of the system quota, so that each API team can own
+// the restriction(s) of its own API(s), from as many stacks as needed.
+// Do not combine with 'system_default_quota' in axwayapi_config, which owns
+// all of the restrictions at once.
+// The id of this resource is "<api_id>/<method>", which is also the form
+// expected by 'terraform import'.
+var TFSystemQuotaRestrictionSchema = schemaMap{
+	"api_id": _FORCENEW(required(_string())),
+	"method": desc(_FORCENEW(optional(_string(), "*")),
+		`the method to limit, or '*' (the default) to limit them all.
+		A method is designated by its name (usually the operationId in the spec) or by "<VERB> <path>", e.g. "GET /pets/{id}".`),
+	"limit": desc(optional(_apply(compressRestriction, _string(validLimit))),
+		"The limit, in the same syntax as in the restrictions of quotas. Exactly one of 'limit' and 'throttle' must be given."),
+	"throttle": desc(optional(_listMax(1, TFThrottle)),
+		`The structured alternative to 'limit', e.g. throttle { messages = 100, per = 2, period = "minute" }`),
+	"method_id": desc(readonly(_string()),
+		"The id of the method, as written in the system quota: the restriction is removed by it, should the method be gone"),
+}
+
+func resourceSystemQuotaRestriction() *schema.Resource {
+	return &schema.Resource{
+		Schema:        TFSystemQuotaRestrictionSchema,
+		CreateContext: resourceSystemQuotaRestrictionCreate,
+		ReadContext:   resourceSystemQuotaRestrictionRead,
+		UpdateContext: resourceSystemQuotaRestrictionUpdate,
+		DeleteContext: resourceSystemQuotaRestrictionDelete,
+		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+			return checkRestrictions(m, []cty.Value{d.GetRawConfig()})
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceSystemQuotaRestrictionCreate(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	wanted, err := expandSystemQuotaRestriction(d, c)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = updateSystemQuota(c, func(quota *client.Quota) error {
+		if i := findRestriction(quota, wanted.Api, wanted.Method); i >= 0 {
+			if sameRestriction(&quota.Restrictions[i], wanted) {
+				// already written, e.g. by a previous attempt.
+				return nil
+			}
+			return fmt.Errorf("the system quota already has a restriction for method '%s' of api %s: import it instead",
+				d.Get("method"), wanted.Api)
+		}
+		quota.Restrictions = append(quota.Restrictions, *wanted)
+		return nil
+	})
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+
+	d.SetId(wanted.Api + "/" + d.Get("method").(string))
+	d.Set("method_id", wanted.Method)
+	return diags
+}
+
+func resourceSystemQuotaRestrictionRead(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	parts := strings.SplitN(d.Id(), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return diag.Errorf("unexpected id '%s', expecting '<api_id>/<method>'", d.Id())
+	}
+	apiId, method := parts[0], parts[1]
+	methodId := method
+	if method != "*" {
+		idx, err := newMethodIndex(c, apiId)
+		if err == nil {
+			methodId, err = idx.resolve(method)
+		}
+		if errors.Is(err, errNoMethod) || isNotFound(err) {
+			// The method, or its whole api, is gone, and so is the restriction.
+			d.SetId("")
+			return diags
+		} else if err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+			return diags
+		}
+	}
+
+	quota, err := c.GetQuota(systemDefaultQuotaId)
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+	i := findRestriction(quota, apiId, methodId)
+	if i < 0 {
+		// Removed outside of terraform: forget it, so that it gets re-created.
+		d.SetId("")
+		return diags
+	}
+	d.Set("api_id", apiId)
+	d.Set("method", method)
+	d.Set("method_id", methodId)
+	if t := d.Get("throttle").([]interface{}); len(t) > 0 {
+		d.Set("throttle", []interface{}{flattenThrottle(quota.Restrictions[i].Config)})
+	} else {
+		d.Set("limit", flattenRestrictionConfig(quota.Restrictions[i].Config))
+	}
+
+	return diags
+}
+
+func resourceSystemQuotaRestrictionUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	wanted, err := expandSystemQuotaRestriction(d, c)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = updateSystemQuota(c, func(quota *client.Quota) error {
+		if i := findRestriction(quota, wanted.Api, wanted.Method); i >= 0 {
+			quota.Restrictions[i] = *wanted
+		} else {
+			quota.Restrictions = append(quota.Restrictions, *wanted)
+		}
+		return nil
+	})
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+	d.Set("method_id", wanted.Method)
+
+	return diags
+}
+
+func resourceSystemQuotaRestrictionDelete(ctx context.Context, d *schema.ResourceData, m interface{}) (diags diag.Diagnostics) {
+	c, err := m.(*ProviderState).GetClient()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// the method may be gone already: the restriction is removed by the id it was written with.
+	apiId, methodId := d.Get("api_id").(string), d.Get("method_id").(string)
+	if methodId == "" {
+		// kept in the state only since its last apply.
+		wanted, err := expandSystemQuotaRestriction(d, c)
+		if errors.Is(err, errNoMethod) || isNotFound(err) {
+			return warn(diags, "the method '%s' of api %s is gone, its restriction cannot be found in the system quota: %v", d.Get("method"), apiId, err)
+		} else if err != nil {
+			return diag.FromErr(err)
+		}
+		methodId = wanted.Method
+	}
+	err = updateSystemQuota(c, func(quota *client.Quota) error {
+		if i := findRestriction(quota, apiId, methodId); i >= 0 {
+			quota.Restrictions = append(quota.Restrictions[:i], quota.Restrictions[i+1:]...)
+		}
+		return nil
+	})
+	if err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+		return diags
+	}
+
+	return diags
+}
+
+// Serializes the updates of the system quota made by this provider.
+var systemQuotaLock sync.Mutex
+
+// updateSystemQuota does a read-modify-write of the system quota.
+// The server offers no version to check against, so the quota is read again
+// just before writing: if it changed in-between, the whole cycle starts over.
+// Once written, the quota is read back: if the change is missing, because another
+// writer overwrote it, the whole cycle starts over too. modify must thus be a no-op
+// on a quota that has the change already.
+// This only narrows the race with writers outside of this process: a change they write
+// between the check and the write of this one is lost, and cannot be detected.
+func updateSystemQuota(c *client.Client, modify func(*client.Quota) error) error {
+	systemQuotaLock.Lock()
+	defer systemQuotaLock.Unlock()
+
+	for attempt := 0; attempt < 5; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+		quota, err := c.GetQuota(systemDefaultQuotaId)
+		if err != nil {
+			return err
+		}
+		changed, err := modifyQuota(quota, modify)
+		if err != nil || !changed {
+			return err
+		}
+		check, err := c.GetQuota(systemDefaultQuotaId)
+		if err != nil {
+			return err
+		}
+		if !sameRestrictions(check, quota, modify) {
+			// Someone else changed the quota meanwhile.
+			continue
+		}
+		if err = c.UpdateQuota(quota); err != nil {
+			return err
+		}
+		written, err := c.GetQuota(systemDefaultQuotaId)
+		if err != nil {
+			return err
+		}
+		if changed, err = modifyQuota(written, modify); err != nil || !changed {
+			return err
+		}
+		// the change was overwritten.
+	}
+	return fmt.Errorf("the system quota keeps changing concurrently, giving up")
+}
+
+// modifyQuota applies modify onto the quota, telling whether its restrictions changed.
+func modifyQuota(quota *client.Quota, modify func(*client.Quota) error) (bool, error) {
+	before, err := json.Marshal(quota.Restrictions)
+	if err != nil {
+		return false, err
+	}
+	if err = modify(quota); err != nil {
+		return false, err
+	}
+	after, err := json.Marshal(quota.Restrictions)
+	if err != nil {
+		return false, err
+	}
+	return string(before) != string(after), nil
+}
+
+// sameRestrictions tells whether check, once modified, has the restrictions of quota.
+func sameRestrictions(check, quota *client.Quota, modify func(*client.Quota) error) bool {
+	if _, err := modifyQuota(check, modify); err != nil {
+		return false
+	}
+	a, _ := json.Marshal(check.Restrictions)
+	b, _ := json.Marshal(quota.Restrictions)
+	return string(a) == string(b)
+}
+
+func sameRestriction(a, b *client.Constraint) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
+
+func findRestriction(quota *client.Quota, apiId, methodId string) int {
+	for i, r := range quota.Restrictions {
+		if r.Api == apiId && r.Method == methodId {
+			return i
+		}
+	}
+	return -1
+}
+
+func expandSystemQuotaRestriction(d *schema.ResourceData, c *client.Client) (*client.Constraint, error) {
+	restrictions := expandRestrictions(schema.NewSet(schema.HashResource(TFRestriction), []interface{}{
+		map[string]interface{}{
+			"api_id":   d.Get("api_id"),
+			"method":   d.Get("method"),
+			"limit":    d.Get("limit"),
+			"throttle": d.Get("throttle"),
+		},
+	}))
+	if err := resolveRestrictionMethods(c, restrictions); err != nil {
+		return nil, err
+	}
+	return &restrictions[0], nil
+}
@@ -24,7 +24,10 @@ var TFUserSchema = schemaMap{
 	"description":      inOut(_string()),
 	"phone":            inOut(_string()),
 	"mobile":           inOut(_string()),
-	"image_jpg":        inOut(_hashedString()),
+	"image_jpg":        _imageJpg(inOut(_hashedString())),
+	"image":            _image(),
+	"image_hash":       _imageHash(),
+	"image_saved":      _imageSaved(),
 	"additional_roles": inOut(_map(schema.TypeString)),
 	"created_on":       readonly(_int()),
 	"state":            readonly(_string()),
@@ -40,6 +43,7 @@ func resourceUser() *schema.Resource {
 		ReadContext:   resourceUserRead,
 		UpdateContext: resourceUserUpdate,
 		DeleteContext: resourceUserDelete,
+		CustomizeDiff: customizeImage,
 	}
 }
 
@@ -59,7 +63,7 @@ func resourceUserCreate(ctx context.Context, d *schema.ResourceData, m interface
 		return diags
 	}
 
-	diags = append(diags, syncImage(d, user, c)...)
+	diags = append(diags, syncImage(ctx, m, d, user, c)...)
 	diags = append(diags, syncPassword(d, user, c)...)
 
 	flattenUser(user, d)
@@ -89,6 +93,7 @@ func resourceUserRead(ctx context.Context, d *schema.ResourceData, m interface{}
 		return diags
 	}
 	flattenUser(user, d)
+	readImage(c, d, user)
 
 	return diags
 }
@@ -107,7 +112,7 @@ func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, m interface
 		return diags
 	}
 
-	diags = append(diags, syncImage(d, user, c)...)
+	diags = append(diags, syncImage(ctx, m, d, user, c)...)
 	diags = append(diags, syncPassword(d, user, c)...)
 	flattenUser(user, d)
 
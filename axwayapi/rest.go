@@ -0,0 +1,168 @@
+package axwayapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+
+	client "github.com/axway-techlab/axwayapi_client/axwayapi"
+)
+
+// The client library only covers part of the API Manager REST API.
+// These helpers reach the remaining endpoints, reusing the host,
+// credentials and transport of the client.
+
+func restDo(c *client.Client, method, url string, body io.Reader, contentType string, expect ...int) ([]byte, error) {
+	req, err := http.NewRequest(method, c.HostURL+"/"+url, body)
+	if err != nil {
+		return nil, err
+	}
+	if len(expect) == 0 {
+		// when no expected status code is given, some classical 20x are implied.
+		expect = []int{http.StatusOK, http.StatusNoContent, http.StatusCreated}
+	}
+	req.SetBasicAuth(c.Auth.Username, c.Auth.Password)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	for _, expected := range expect {
+		if res.StatusCode == expected {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("status: %d, body: %s", res.StatusCode, b)
+}
+
+// isNotFound tells whether the error, or one it wraps, is a 404 answer,
+// as reported by both the client and the helpers here.
+func isNotFound(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if strings.HasPrefix(err.Error(), fmt.Sprintf("status: %d,", http.StatusNotFound)) {
+			return true
+		}
+	}
+	return false
+}
+
+func restGet(c *client.Client, object interface{}, url string, expect ...int) error {
+	b, err := restDo(c, "GET", url, nil, "", expect...)
+	if err != nil {
+		return err
+	}
+	if object == nil || len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, object)
+}
+
+func restSend(c *client.Client, method string, object interface{}, url string, expect ...int) error {
+	rb, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	b, err := restDo(c, method, url, strings.NewReader(string(rb)), "application/json", expect...)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, object)
+}
+
+func restPost(c *client.Client, object interface{}, url string, expect ...int) error {
+	return restSend(c, "POST", object, url, expect...)
+}
+
+func restPut(c *client.Client, object interface{}, url string, expect ...int) error {
+	return restSend(c, "PUT", object, url, expect...)
+}
+
+// restPostForm posts an url-encoded form, and reads the JSON answer into object.
+func restPostForm(c *client.Client, object interface{}, url string, form url.Values, expect ...int) error {
+	b, err := restDo(c, "POST", url, strings.NewReader(form.Encode()), "application/x-www-form-urlencoded", expect...)
+	if err != nil {
+		return err
+	}
+	if object == nil || len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, object)
+}
+
+// A file sent in a multipart form.
+type restFile struct {
+	field, name, contentType string
+	content                  []byte
+}
+
+// restPostParts posts a multipart form of the given fields and file, giving back the raw answer.
+func restPostParts(c *client.Client, url string, fields map[string]string, file restFile, expect ...int) ([]byte, error) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, file.field, file.name))
+	h.Set("Content-Type", file.contentType)
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = part.Write(file.content); err != nil {
+		return nil, err
+	}
+	for k, v := range fields {
+		if err = w.WriteField(k, v); err != nil {
+			return nil, err
+		}
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return restDo(c, "POST", url, body, w.FormDataContentType(), expect...)
+}
+
+func restDelete(c *client.Client, url string, expect ...int) error {
+	_, err := restDo(c, "DELETE", url, nil, "", expect...)
+	return err
+}
+
+// download reads the content at the url, with the download client of the provider.
+func download(ctx context.Context, m interface{}, u string) ([]byte, error) {
+	httpClient := &http.Client{Timeout: downloadTimeout}
+	if prov, ok := m.(*ProviderState); ok {
+		httpClient = prov.downloadClient()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status: %d from %s", res.StatusCode, u)
+	}
+	return ioutil.ReadAll(res.Body)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -17,6 +18,16 @@ func resource(s schemaMap) *schema.Resource {
 	return &schema.Resource{Schema: s}
 }
 
+// keys gives the attributes, sorted.
+func (s schemaMap) keys() []string {
+	r := make([]string, 0, len(s))
+	for k := range s {
+		r = append(r, k)
+	}
+	sort.Strings(r)
+	return r
+}
+
 //--
 func inOut(schema *schema.Schema) *schema.Schema {
 	schema.Required = false
@@ -82,6 +93,14 @@ func _FORCENEW(schema *schema.Schema) *schema.Schema {
 	return schema
 }
 
+// _onCreateOnly ignores the changes of the attribute once the resource is created.
+func _onCreateOnly(s *schema.Schema) *schema.Schema {
+	s.DiffSuppressFunc = func(k, old, new string, d *schema.ResourceData) bool {
+		return d.Id() != ""
+	}
+	return s
+}
+
 func _asBlock(s *schema.Schema) *schema.Schema {
 	s.ConfigMode = schema.SchemaConfigModeBlock
 	return s
@@ -176,6 +195,9 @@ func _apply(ser func(interface{}) string, s *schema.Schema) *schema.Schema {
 func _pset(setValuesType schema.ValueType) *schema.Schema {
 	return &schema.Schema{Type: schema.TypeSet, Elem: &schema.Schema{Type: setValuesType}}
 }
+func _psetOf(elem *schema.Schema) *schema.Schema {
+	return &schema.Schema{Type: schema.TypeSet, Elem: elem}
+}
 func _set(s *schema.Resource) *schema.Schema {
 	return &schema.Schema{Type: schema.TypeSet, Elem: s}
 }
@@ -4,34 +4,30 @@ import (
 	"encoding/json"
 	"fmt"
 	"hash/fnv"
+	"strings"
 
-	client "github.com/axway-techlab/axwayapi_client/axwayapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 type flattenMap = map[string]interface{}
 
-func syncImage(d *schema.ResourceData, object client.WithId, c *client.Client) (diags diag.Diagnostics) {
-	// image is a special case.
-	if d.HasChange("image_jpg") {
-		err := c.UpdateImageFor(object, d.Get("image_jpg").(string))
-		if nil != err {
-			diags = warn(diags, "updating image for %T %s failed: %v", object, object.GetId(), err)
-		}
-	}
-	return diags
-}
-
-func guard(diags diag.Diagnostics, f func(*client.Frontend) error, frontend *client.Frontend) diag.Diagnostics {
-	if err := f(frontend); nil != err {
-		diags = append(diags, diag.FromErr(err)...)
-	}
-	return diags
-}
 func warn(diags diag.Diagnostics, warn string, params ...interface{}) diag.Diagnostics {
 	return append(diags, diag.Diagnostic{Severity: diag.Warning, Summary: fmt.Sprintf(warn, params...)})
 }
+// Sub-resources (e.g. the permissions of an application) are identified
+// by the ids of their parents and their own, joined with '/'.
+func splitId(id string, names ...string) ([]string, error) {
+	parts := strings.Split(id, "/")
+	ok := len(parts) == len(names)
+	for _, p := range parts {
+		ok = ok && p != ""
+	}
+	if !ok {
+		return nil, fmt.Errorf("unexpected id '%s', expecting '<%s>'", id, strings.Join(names, ">/<"))
+	}
+	return parts, nil
+}
 func toParameters(params map[string]interface{}) map[string]interface{} {
 	r := make(map[string]interface{}, len(params))
 	for k, v := range params {
@@ -80,6 +76,9 @@ func toTags(tags *schema.Set) map[string][]string {
 //--
 func toStringArray(array interface{}) []string {
 	// Joys of Golang...
+	if set, ok := array.(*schema.Set); ok {
+		array = set.List()
+	}
 	a := array.([]interface{})
 	s := make([]string, len(a))
 	for i, o := range a {